@@ -29,6 +29,8 @@ import (
 	nodeDrain "github.com/litmuschaos/litmus-go/experiments/generic/node-drain/experiment"
 	nodeIOStress "github.com/litmuschaos/litmus-go/experiments/generic/node-io-stress/experiment"
 	nodeMemoryHog "github.com/litmuschaos/litmus-go/experiments/generic/node-memory-hog/experiment"
+	nodeNetworkLatency "github.com/litmuschaos/litmus-go/experiments/generic/node-network-latency/experiment"
+	nodeNetworkLoss "github.com/litmuschaos/litmus-go/experiments/generic/node-network-loss/experiment"
 	nodeRestart "github.com/litmuschaos/litmus-go/experiments/generic/node-restart/experiment"
 	nodeTaint "github.com/litmuschaos/litmus-go/experiments/generic/node-taint/experiment"
 	podAutoscaler "github.com/litmuschaos/litmus-go/experiments/generic/pod-autoscaler/experiment"
@@ -47,10 +49,14 @@ import (
 	podMemoryHogExec "github.com/litmuschaos/litmus-go/experiments/generic/pod-memory-hog-exec/experiment"
 	podMemoryHog "github.com/litmuschaos/litmus-go/experiments/generic/pod-memory-hog/experiment"
 	podNetworkCorruption "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-corruption/experiment"
+	podNetworkDegradation "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-degradation/experiment"
 	podNetworkDuplication "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-duplication/experiment"
 	podNetworkLatency "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-latency/experiment"
 	podNetworkLoss "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-loss/experiment"
 	podNetworkPartition "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-partition/experiment"
+	podNetworkRateLimit "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-rate-limit/experiment"
+	podNetworkReorder "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-reorder/experiment"
+	podNetworkSplitBrain "github.com/litmuschaos/litmus-go/experiments/generic/pod-network-split-brain/experiment"
 	kafkaBrokerPodFailure "github.com/litmuschaos/litmus-go/experiments/kafka/kafka-broker-pod-failure/experiment"
 	ebsLossByID "github.com/litmuschaos/litmus-go/experiments/kube-aws/ebs-loss-by-id/experiment"
 	ebsLossByTag "github.com/litmuschaos/litmus-go/experiments/kube-aws/ebs-loss-by-tag/experiment"
@@ -108,6 +114,10 @@ func main() {
 		nodeIOStress.NodeIOStress(clients)
 	case "node-memory-hog":
 		nodeMemoryHog.NodeMemoryHog(clients)
+	case "node-network-latency":
+		nodeNetworkLatency.NodeNetworkLatency(clients)
+	case "node-network-loss":
+		nodeNetworkLoss.NodeNetworkLoss(clients)
 	case "node-taint":
 		nodeTaint.NodeTaint(clients)
 	case "pod-autoscaler":
@@ -122,6 +132,8 @@ func main() {
 		podMemoryHogExec.PodMemoryHogExec(clients)
 	case "pod-network-corruption":
 		podNetworkCorruption.PodNetworkCorruption(clients)
+	case "pod-network-degradation":
+		podNetworkDegradation.PodNetworkDegradation(clients)
 	case "pod-network-duplication":
 		podNetworkDuplication.PodNetworkDuplication(clients)
 	case "pod-network-latency":
@@ -130,6 +142,12 @@ func main() {
 		podNetworkLoss.PodNetworkLoss(clients)
 	case "pod-network-partition":
 		podNetworkPartition.PodNetworkPartition(clients)
+	case "pod-network-rate-limit":
+		podNetworkRateLimit.PodNetworkRateLimit(clients)
+	case "pod-network-reorder":
+		podNetworkReorder.PodNetworkReorder(clients)
+	case "pod-network-split-brain":
+		podNetworkSplitBrain.PodNetworkSplitBrain(clients)
 	case "pod-memory-hog":
 		podMemoryHog.PodMemoryHog(clients)
 	case "pod-cpu-hog":
@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"os"
 	// Uncomment to load all auth plugins
 	// _ "k8s.io/client-go/plugin/pkg/client/auth"
 
@@ -16,6 +17,8 @@ import (
 	httpChaos "github.com/litmuschaos/litmus-go/chaoslib/litmus/http-chaos/helper"
 	networkChaos "github.com/litmuschaos/litmus-go/chaoslib/litmus/network-chaos/helper"
 	dnsChaos "github.com/litmuschaos/litmus-go/chaoslib/litmus/pod-dns-chaos/helper"
+	dnsInterceptor "github.com/litmuschaos/litmus-go/chaoslib/litmus/pod-dns-chaos/interceptor"
+	networkPartition "github.com/litmuschaos/litmus-go/chaoslib/litmus/pod-network-partition/helper"
 	stressChaos "github.com/litmuschaos/litmus-go/chaoslib/litmus/stress-chaos/helper"
 
 	"github.com/litmuschaos/litmus-go/pkg/clients"
@@ -36,6 +39,13 @@ func main() {
 
 	clients := clients.ClientSets{}
 
+	// the dns interceptor runs inside the network namespace of the target container, spawned by the dns helper
+	// it doesn't interact with the kubernetes apiserver, so it is invoked before deriving the kubeconfig
+	if len(os.Args) == 3 && os.Args[1] == "-name" && os.Args[2] == dnsInterceptor.Name {
+		dnsInterceptor.Interceptor()
+		return
+	}
+
 	// parse the helper name
 	helperName := flag.String("name", "", "name of the helper pod")
 
@@ -61,6 +71,8 @@ func main() {
 		networkChaos.Helper(clients)
 	case "http-chaos":
 		httpChaos.Helper(clients)
+	case "network-partition":
+		networkPartition.Helper(clients)
 
 	default:
 		log.Errorf("Unsupported -name %v, please provide the correct value of -name args", *helperName)
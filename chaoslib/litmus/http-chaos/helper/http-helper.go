@@ -114,6 +114,15 @@ func prepareK8sHttpChaos(experimentsDetails *experimentTypes.ExperimentDetails,
 		if err = injectChaos(experimentsDetails, t); err != nil {
 			return stacktrace.Propagate(err, "could not inject chaos")
 		}
+		// verifying that the traffic is redirected to the proxy inside target container
+		if experimentsDetails.FaultVerification == "true" {
+			if err = verifyChaos(experimentsDetails, t); err != nil {
+				if revertErr := revertChaos(experimentsDetails, t); revertErr != nil {
+					return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s,%s]", stacktrace.RootCause(err).Error(), stacktrace.RootCause(revertErr).Error())}
+				}
+				return stacktrace.Propagate(err, "could not verify chaos")
+			}
+		}
 		log.Infof("successfully injected chaos on target: {name: %s, namespace: %v, container: %v}", t.Name, t.Namespace, t.TargetContainer)
 		if err = result.AnnotateChaosResult(resultDetails.Name, chaosDetails.ChaosNamespace, "injected", "pod", t.Name); err != nil {
 			if revertErr := revertChaos(experimentsDetails, t); revertErr != nil {
@@ -286,6 +295,7 @@ func getENV(experimentDetails *experimentTypes.ExperimentDetails) {
 	experimentDetails.TargetServicePort, _ = strconv.Atoi(types.Getenv("TARGET_SERVICE_PORT", ""))
 	experimentDetails.ProxyPort, _ = strconv.Atoi(types.Getenv("PROXY_PORT", ""))
 	experimentDetails.Toxicity, _ = strconv.Atoi(types.Getenv("TOXICITY", "100"))
+	experimentDetails.FaultVerification = types.Getenv("FAULT_VERIFICATION", "true")
 }
 
 // abortWatcher continuously watch for the abort signals
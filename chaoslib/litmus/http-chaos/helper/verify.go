@@ -0,0 +1,112 @@
+package helper
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/http-chaos/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+// tcpListenState is the state of the listening sockets inside the /proc/<pid>/net/tcp file
+const tcpListenState = "0A"
+
+// verifyChaos verifies that the redirect rule and the proxy listener are present inside the network namespace of the target container
+func verifyChaos(experimentDetails *experimentTypes.ExperimentDetails, t targetDetails) error {
+	log.Infof("[Verification]: Verifying the http chaos on target: {name: %s, namespace: %v, container: %v}", t.Name, t.Namespace, t.TargetContainer)
+
+	rules, err := exec.Command("/bin/bash", "-c", fmt.Sprintf("sudo nsenter -t %d -n iptables -t nat -S PREROUTING", t.Pid)).CombinedOutput()
+	if err != nil {
+		return verificationError(t, fmt.Sprintf("unable to list the iptables rules: %s", strings.TrimSpace(string(rules))))
+	}
+	if !hasRedirectRule(string(rules), experimentDetails.NetworkInterface, experimentDetails.TargetServicePort, experimentDetails.ProxyPort) {
+		return verificationError(t, fmt.Sprintf("REDIRECT rule from %d to %d port is not found on %s interface", experimentDetails.TargetServicePort, experimentDetails.ProxyPort, experimentDetails.NetworkInterface))
+	}
+
+	// the proxy may take a while to start listening on the proxy port
+	retry := 3
+	for {
+		listening, err := isListening(t.Pid, experimentDetails.ProxyPort)
+		if err != nil {
+			return verificationError(t, fmt.Sprintf("unable to list the listening sockets: %s", err.Error()))
+		}
+		if listening {
+			break
+		}
+		if retry--; retry == 0 {
+			return verificationError(t, fmt.Sprintf("proxy is not listening on %d port", experimentDetails.ProxyPort))
+		}
+		time.Sleep(1 * time.Second)
+	}
+
+	log.Infof("[Verification]: The http chaos is verified on target: {name: %s, namespace: %v, container: %v}", t.Name, t.Namespace, t.TargetContainer)
+	return nil
+}
+
+// hasRedirectRule checks whether the iptables rules contain the REDIRECT rule for the target service port
+func hasRedirectRule(rules, netInterface string, targetPort, proxyPort int) bool {
+	for _, rule := range strings.Split(rules, "\n") {
+		if strings.Contains(rule, "-j REDIRECT") &&
+			strings.Contains(rule, "-i "+netInterface+" ") &&
+			strings.Contains(rule, fmt.Sprintf("--dport %d ", targetPort)) &&
+			strings.HasSuffix(strings.TrimSpace(rule), fmt.Sprintf("--to-ports %d", proxyPort)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isListening checks whether any socket is listening on the given port inside the network namespace of the given pid
+func isListening(pid, port int) (bool, error) {
+	for _, file := range []string{"tcp", "tcp6"} {
+		listening, err := isListeningInFile(fmt.Sprintf("/proc/%d/net/%s", pid, file), port)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return false, err
+		}
+		if listening {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isListeningInFile parse the sockets from the /proc/<pid>/net/tcp(6) file
+// the local address is in <ip>:<port> format, where port is in hex
+func isListeningInFile(path string, port int) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	s := bufio.NewScanner(file)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 4 || fields[3] != tcpListenState {
+			continue
+		}
+		address := strings.Split(fields[1], ":")
+		localPort, err := strconv.ParseInt(address[len(address)-1], 16, 32)
+		if err != nil {
+			continue
+		}
+		if int(localPort) == port {
+			return true, nil
+		}
+	}
+	return false, s.Err()
+}
+
+// verificationError returns the chaos inject error for the failed verification
+func verificationError(t targetDetails, reason string) error {
+	return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Source: t.Source, Target: fmt.Sprintf("{podName: %s, namespace: %s, container: %s}", t.Name, t.Namespace, t.TargetContainer), Reason: fmt.Sprintf("fault verification failed: %s", reason)}
+}
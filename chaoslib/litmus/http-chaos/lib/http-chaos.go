@@ -279,6 +279,7 @@ func getPodEnv(experimentsDetails *experimentTypes.ExperimentDetails, targets, a
 		SetEnv("TARGET_SERVICE_PORT", strconv.Itoa(experimentsDetails.TargetServicePort)).
 		SetEnv("PROXY_PORT", strconv.Itoa(experimentsDetails.ProxyPort)).
 		SetEnv("TOXICITY", strconv.Itoa(experimentsDetails.Toxicity)).
+		SetEnv("FAULT_VERIFICATION", experimentsDetails.FaultVerification).
 		SetEnvFromDownwardAPI("v1", "metadata.name")
 
 	return envDetails.ENV
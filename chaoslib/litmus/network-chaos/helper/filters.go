@@ -0,0 +1,132 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// u32Filter is the u32 filter, which classifies the traffic into the bands of the prio qdisc
+type u32Filter struct {
+	// protocol is the ethernet protocol of the filter, it can be ip, ipv6 or all
+	protocol string
+	prio     int
+	matches  []string
+	flowID   string
+}
+
+// addressFamily contains the tc protocol and u32 selectors of the address family
+type addressFamily struct {
+	protocol string
+	selector string
+	// includePrio and excludePrio are the priorities of the filters
+	// tc doesn't allow the filters of different protocols with the same priority
+	// so each family uses its own priority and exclusions are evaluated before the inclusions
+	includePrio int
+	excludePrio int
+	// destinationPrios are the priorities of the destination ip filters, which are used alternately
+	// so that the filters of the refreshed destinations are added before removing the stale ones
+	destinationPrios [2]int
+}
+
+var (
+	ipv4 = addressFamily{protocol: "ip", selector: "ip", includePrio: 3, excludePrio: 1, destinationPrios: [2]int{10, 12}}
+	ipv6 = addressFamily{protocol: "ipv6", selector: "ip6", includePrio: 4, excludePrio: 2, destinationPrios: [2]int{11, 13}}
+	// catchAllPrio is the priority of the filter, which matches all the traffic
+	catchAllPrio = 5
+)
+
+// protocolNumbers contains the ip protocol numbers of the supported protocols for both the families
+var protocolNumbers = map[string]map[string]int{
+	"tcp":  {"ip": 6, "ip6": 6},
+	"udp":  {"ip": 17, "ip6": 17},
+	"icmp": {"ip": 1, "ip6": 58},
+}
+
+// isFilterRequired checks whether the traffic of the target needs to be classified, otherwise the chaos is injected on all the traffic
+func isFilterRequired(target targetDetails) bool {
+	return len(getDestinationIPs(target.DestinationIps)) != 0 || len(sPorts) != 0 || len(dPorts) != 0 || len(excludedIps) != 0 || len(excludedPorts) != 0 || protocol != "all" || refreshEnabled
+}
+
+// command returns the tc command to add the filter on the given device
+func (f u32Filter) command(pid int, device string) string {
+	return fmt.Sprintf("sudo nsenter -t %v -n tc filter add dev %v protocol %v parent 1:0 prio %v u32 %v flowid %v", pid, device, f.protocol, f.prio, strings.Join(f.matches, " "), f.flowID)
+}
+
+// getFilters derives the u32 filters for the target
+// the excluded ips and ports are redirected to the 1:1 band, without any chaos
+// the destination ips and ports of the selected protocol are redirected to the 1:3 band, where chaos is injected
+// all the traffic of the selected protocol is redirected to the 1:3 band, if neither destination ips nor ports are provided
+// ipMatch is the direction of the ips inside the packet, it is dst for egress and src for ingress
+func getFilters(ipMatch string, ips []string) []u32Filter {
+	var filters []u32Filter
+	families := []addressFamily{ipv4, ipv6}
+
+	// exclusions are evaluated first, as they have the lower priority
+	for _, ip := range excludedIps {
+		family := getFamily(ip)
+		filters = append(filters, u32Filter{protocol: family.protocol, prio: family.excludePrio, matches: []string{fmt.Sprintf("match %v %v %v", family.selector, ipMatch, ip)}, flowID: "1:1"})
+	}
+	for _, port := range excludedPorts {
+		for _, family := range families {
+			for _, portMatch := range []string{"sport", "dport"} {
+				filters = append(filters, u32Filter{protocol: family.protocol, prio: family.excludePrio, matches: []string{fmt.Sprintf("match %v %v %v 0xffff", family.selector, portMatch, port)}, flowID: "1:1"})
+			}
+		}
+	}
+
+	filters = append(filters, getDestinationFilters(ipMatch, ips, 0)...)
+	for _, family := range families {
+		for _, port := range sPorts {
+			filters = append(filters, u32Filter{protocol: family.protocol, prio: family.includePrio, matches: append([]string{fmt.Sprintf("match %v sport %v 0xffff", family.selector, port)}, getProtocolMatches(family)...), flowID: "1:3"})
+		}
+		for _, port := range dPorts {
+			filters = append(filters, u32Filter{protocol: family.protocol, prio: family.includePrio, matches: append([]string{fmt.Sprintf("match %v dport %v 0xffff", family.selector, port)}, getProtocolMatches(family)...), flowID: "1:3"})
+		}
+	}
+
+	if len(ips) == 0 && len(sPorts) == 0 && len(dPorts) == 0 {
+		filters = append(filters, getMatchAllFilters()...)
+	}
+	return filters
+}
+
+// getMatchAllFilters derives the filters, which redirect all the traffic of the selected protocol to the 1:3 band
+func getMatchAllFilters() []u32Filter {
+	if protocol == "all" {
+		return []u32Filter{{protocol: "all", prio: catchAllPrio, matches: []string{"match u32 0 0"}, flowID: "1:3"}}
+	}
+	var filters []u32Filter
+	for _, family := range []addressFamily{ipv4, ipv6} {
+		filters = append(filters, u32Filter{protocol: family.protocol, prio: family.includePrio, matches: getProtocolMatches(family), flowID: "1:3"})
+	}
+	return filters
+}
+
+// getDestinationFilters derives the filters of the destination ips for the given generation
+// the generation is incremented every time the destination ips are reconciled
+func getDestinationFilters(ipMatch string, ips []string, generation int) []u32Filter {
+	var filters []u32Filter
+	for _, ip := range ips {
+		family := getFamily(ip)
+		filters = append(filters, u32Filter{protocol: family.protocol, prio: family.destinationPrios[generation%2], matches: append([]string{fmt.Sprintf("match %v %v %v", family.selector, ipMatch, ip)}, getProtocolMatches(family)...), flowID: "1:3"})
+	}
+	return filters
+}
+
+// getProtocolMatches returns the u32 match for the selected protocol
+// the ip6 protocol matches the next header, so it doesn't match the packets with extension headers
+func getProtocolMatches(family addressFamily) []string {
+	numbers, ok := protocolNumbers[protocol]
+	if !ok {
+		return nil
+	}
+	return []string{fmt.Sprintf("match %v protocol %v 0xff", family.selector, numbers[family.selector])}
+}
+
+// getFamily returns the address family of the ip or cidr
+func getFamily(ip string) addressFamily {
+	if strings.Contains(ip, ":") {
+		return ipv6
+	}
+	return ipv4
+}
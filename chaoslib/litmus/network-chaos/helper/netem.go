@@ -25,6 +25,11 @@ import (
 const (
 	qdiscNotFound    = "Cannot delete qdisc with handle of zero"
 	qdiscNoFileFound = "RTNETLINK answers: No such file or directory"
+	// qdiscInvalidArgument is returned while deleting the ingress qdisc, which doesn't exist
+	qdiscInvalidArgument = "RTNETLINK answers: Invalid argument"
+	deviceNotFound       = "Cannot find device"
+	// ifbDevice is the intermediate functional block device, used to inject chaos in ingress direction
+	ifbDevice = "litmus-ifb"
 )
 
 var (
@@ -32,7 +37,22 @@ var (
 	inject, abort chan os.Signal
 )
 
-var destIps, sPorts, dPorts []string
+var sPorts, dPorts, excludedIps, excludedPorts []string
+
+// protocol is the protocol of the traffic, which is affected by the chaos
+var protocol string
+
+// targetType is the type of the targets, it can be pod or node
+// the chaos is injected inside the host network namespace for the nodes
+var targetType string
+
+// qdisc is the queueing discipline used to inject the chaos, it defaults to netem
+// direction is the direction of the traffic, it can be egress or ingress
+var qdisc, direction string
+
+// refreshEnabled is true, if the destination hosts are refreshed during the chaos
+// the traffic is always classified in that case, so that the destination filters can be reconciled
+var refreshEnabled bool
 
 // Helper injects the network chaos
 func Helper(clients clients.ClientSets) {
@@ -85,9 +105,18 @@ func preparePodNetworkChaos(experimentsDetails *experimentTypes.ExperimentDetail
 		return cerrors.Error{ErrorCode: cerrors.ErrorTypeHelper, Source: chaosDetails.ChaosPodName, Reason: "no target found, provide atleast one target"}
 	}
 
+	schedule, err := getSchedule(experimentsDetails)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not get the chaos schedule")
+	}
+
 	var targets []targetDetails
 
 	for _, t := range strings.Split(targetEnv, ";") {
+		if targetType == "node" {
+			targets = append(targets, getNodeTarget(t, chaosDetails.ChaosPodName))
+			continue
+		}
 		target := strings.Split(t, ":")
 		if len(target) != 4 {
 			return cerrors.Error{ErrorCode: cerrors.ErrorTypeHelper, Source: chaosDetails.ChaosPodName, Reason: fmt.Sprintf("unsupported target format: '%v'", targets)}
@@ -96,6 +125,7 @@ func preparePodNetworkChaos(experimentsDetails *experimentTypes.ExperimentDetail
 			Name:            target[0],
 			Namespace:       target[1],
 			TargetContainer: target[2],
+			ServiceMesh:     target[3],
 			DestinationIps:  getDestIps(target[3]),
 			Source:          chaosDetails.ChaosPodName,
 		}
@@ -114,8 +144,10 @@ func preparePodNetworkChaos(experimentsDetails *experimentTypes.ExperimentDetail
 		targets = append(targets, td)
 	}
 
+	scheduler := newScheduleRunner()
+
 	// watching for the abort signal and revert the chaos
-	go abortWatcher(targets, experimentsDetails.NetworkInterface, resultDetails.Name, chaosDetails.ChaosNamespace)
+	go abortWatcher(targets, experimentsDetails.NetworkInterface, resultDetails.Name, chaosDetails.ChaosNamespace, scheduler)
 
 	select {
 	case <-inject:
@@ -127,10 +159,22 @@ func preparePodNetworkChaos(experimentsDetails *experimentTypes.ExperimentDetail
 	for _, t := range targets {
 		// injecting network chaos inside target container
 		if err = injectChaos(experimentsDetails.NetworkInterface, t); err != nil {
+			if killed, revertErr := killnetem(t, experimentsDetails.NetworkInterface); !killed && revertErr != nil {
+				return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s,%s]", stacktrace.RootCause(err).Error(), stacktrace.RootCause(revertErr).Error())}
+			}
 			return stacktrace.Propagate(err, "could not inject chaos")
 		}
+		// verifying that the netem rules are applied inside target container
+		if experimentsDetails.FaultVerification == "true" {
+			if err = verifyChaos(experimentsDetails.NetworkInterface, experimentsDetails.VerificationRTTTarget, t); err != nil {
+				if killed, revertErr := killnetem(t, experimentsDetails.NetworkInterface); !killed && revertErr != nil {
+					return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s,%s]", stacktrace.RootCause(err).Error(), stacktrace.RootCause(revertErr).Error())}
+				}
+				return stacktrace.Propagate(err, "could not verify chaos")
+			}
+		}
 		log.Infof("successfully injected chaos on target: {name: %s, namespace: %v, container: %v}", t.Name, t.Namespace, t.TargetContainer)
-		if err = result.AnnotateChaosResult(resultDetails.Name, chaosDetails.ChaosNamespace, "injected", "pod", t.Name); err != nil {
+		if err = result.AnnotateChaosResult(resultDetails.Name, chaosDetails.ChaosNamespace, "injected", targetType, t.Name); err != nil {
 			if _, revertErr := killnetem(t, experimentsDetails.NetworkInterface); err != nil {
 				return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s,%s]", stacktrace.RootCause(err).Error(), stacktrace.RootCause(revertErr).Error())}
 			}
@@ -146,10 +190,26 @@ func preparePodNetworkChaos(experimentsDetails *experimentTypes.ExperimentDetail
 
 	log.Infof("[Chaos]: Waiting for %vs", experimentsDetails.ChaosDuration)
 
-	common.WaitForDuration(experimentsDetails.ChaosDuration)
+	// the schedule changes the chaos qdisc of the targets in place, during the chaos duration
+	if schedule != nil {
+		scheduleTargets := append([]targetDetails(nil), targets...)
+		scheduler.start(func(stop <-chan struct{}) {
+			runSchedule(stop, experimentsDetails, schedule, scheduleTargets, clients, chaosDetails)
+		})
+	}
+
+	// the destinations are refreshed during the chaos, only if the destination hosts are provided
+	if refreshEnabled {
+		refreshDestinations(experimentsDetails, targets, clients, chaosDetails)
+	} else {
+		common.WaitForDuration(experimentsDetails.ChaosDuration)
+	}
 
 	log.Info("[Chaos]: duration is over, reverting chaos")
 
+	// the schedule is stopped before the revert, otherwise it may re-add the chaos qdisc
+	scheduler.halt()
+
 	var errList []string
 	for _, t := range targets {
 		// cleaning the netem process after chaos injection
@@ -159,7 +219,7 @@ func preparePodNetworkChaos(experimentsDetails *experimentTypes.ExperimentDetail
 			continue
 		}
 		if killed && err == nil {
-			if err = result.AnnotateChaosResult(resultDetails.Name, chaosDetails.ChaosNamespace, "reverted", "pod", t.Name); err != nil {
+			if err = result.AnnotateChaosResult(resultDetails.Name, chaosDetails.ChaosNamespace, "reverted", targetType, t.Name); err != nil {
 				errList = append(errList, err.Error())
 			}
 		}
@@ -178,27 +238,29 @@ func injectChaos(netInterface string, target targetDetails) error {
 
 	netemCommands := os.Getenv("NETEM_COMMAND")
 
-	if len(destIps) == 0 && len(sPorts) == 0 && len(dPorts) == 0 {
-		tc := fmt.Sprintf("sudo nsenter -t %d -n tc qdisc replace dev %s root netem %v", target.Pid, netInterface, netemCommands)
+	// in ingress direction, the incoming traffic is redirected to the ifb device
+	// and the chaos is injected on the egress of the ifb device
+	device, ipMatch := getChaosDevice(netInterface)
+	if direction == "ingress" {
+		if err := redirectIngress(netInterface, target); err != nil {
+			return err
+		}
+	}
+
+	if !isFilterRequired(target) {
+		tc := fmt.Sprintf("sudo nsenter -t %d -n tc qdisc replace dev %s root %v %v", target.Pid, device, qdisc, netemCommands)
 		log.Info(tc)
 		if err := common.RunBashCommand(tc, "failed to create tc rules", target.Source); err != nil {
 			return err
 		}
 	} else {
 
-		ips := strings.Split(target.DestinationIps, ",")
-		var uniqueIps []string
-
 		// removing duplicates ips from the list, if any
-		for i := range ips {
-			if !common.Contains(ips[i], uniqueIps) {
-				uniqueIps = append(uniqueIps, ips[i])
-			}
-		}
+		uniqueIps := getDestinationIPs(target.DestinationIps)
 
 		// Create a priority-based queue
 		// This instantly creates classes 1:1, 1:2, 1:3
-		priority := fmt.Sprintf("sudo nsenter -t %v -n tc qdisc replace dev %v root handle 1: prio", target.Pid, netInterface)
+		priority := fmt.Sprintf("sudo nsenter -t %v -n tc qdisc replace dev %v root handle 1: prio", target.Pid, device)
 		log.Info(priority)
 		if err := common.RunBashCommand(priority, "failed to create priority-based queue", target.Source); err != nil {
 			return err
@@ -206,58 +268,124 @@ func injectChaos(netInterface string, target targetDetails) error {
 
 		// Add queueing discipline for 1:3 class.
 		// No traffic is going through 1:3 yet
-		traffic := fmt.Sprintf("sudo nsenter -t %v -n tc qdisc replace dev %v parent 1:3 netem %v", target.Pid, netInterface, netemCommands)
+		traffic := fmt.Sprintf("sudo nsenter -t %v -n tc qdisc replace dev %v parent 1:3 %v %v", target.Pid, device, qdisc, netemCommands)
 		log.Info(traffic)
-		if err := common.RunBashCommand(traffic, "failed to create netem queueing discipline", target.Source); err != nil {
+		if err := common.RunBashCommand(traffic, fmt.Sprintf("failed to create %v queueing discipline", qdisc), target.Source); err != nil {
 			return err
 		}
 
-		for _, ip := range uniqueIps {
-			// redirect traffic to specific IP through band 3
-			tc := fmt.Sprintf("sudo nsenter -t %v -n tc filter add dev %v protocol ip parent 1:0 prio 3 u32 match ip dst %v flowid 1:3", target.Pid, netInterface, ip)
-			if strings.Contains(ip, ":") {
-				tc = fmt.Sprintf("sudo nsenter -t %v -n tc filter add dev %v protocol ip parent 1:0 prio 3 u32 match ip6 dst %v flowid 1:3", target.Pid, netInterface, ip)
-			}
+		// redirect the matching traffic through band 3
+		for _, filter := range getFilters(ipMatch, uniqueIps) {
+			tc := filter.command(target.Pid, device)
 			log.Info(tc)
-			if err := common.RunBashCommand(tc, "failed to create destination ips match filters", target.Source); err != nil {
+			if err := common.RunBashCommand(tc, "failed to create the match filters", target.Source); err != nil {
 				return err
 			}
 		}
+	}
 
-		for _, port := range sPorts {
-			//redirect traffic to specific sport through band 3
-			tc := fmt.Sprintf("sudo nsenter -t %v -n tc filter add dev %v protocol ip parent 1:0 prio 3 u32 match ip sport %v 0xffff flowid 1:3", target.Pid, netInterface, port)
-			log.Info(tc)
-			if err := common.RunBashCommand(tc, "failed to create source ports match filters", target.Source); err != nil {
-				return err
-			}
+	log.Infof("chaos injected successfully on {pod: %v, container: %v}", target.Name, target.TargetContainer)
+	return nil
+}
+
+// recordChaosEvent records the change of the chaos during the chaos duration as the event inside the chaosengine
+func recordChaosEvent(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails, msg, eventType string) {
+	if experimentsDetails.EngineName == "" {
+		return
+	}
+	// the events are recorded concurrently by the schedule and the destination refresh, so each event uses its own details
+	eventsDetails := types.EventDetails{}
+	types.SetEngineEventAttributes(&eventsDetails, types.ChaosInject, msg, eventType, chaosDetails)
+	events.GenerateEvents(&eventsDetails, clients, chaosDetails, "ChaosEngine")
+}
+
+// getChaosDevice returns the device where the chaos is injected, along with the direction of the destination ips inside the packets
+// in ingress direction, the chaos is injected on the ifb device and the destination ips are matched as the source of the packets
+func getChaosDevice(netInterface string) (string, string) {
+	if direction == "ingress" {
+		return ifbDevice, "src"
+	}
+	return netInterface, "dst"
+}
+
+// redirectIngress creates the ifb device inside the network namespace of the target container
+// and redirects all the incoming traffic of the network interface to the ifb device
+func redirectIngress(netInterface string, target targetDetails) error {
+	// the ifb device and the ingress qdisc are left behind, if the previous run has been aborted before the revert
+	if err := removeStaleIngress(netInterface, target); err != nil {
+		return err
+	}
+
+	commands := []struct {
+		command, failMsg string
+	}{
+		{fmt.Sprintf("ip link add %v type ifb", ifbDevice), "failed to create the ifb device, ensure that the ifb module is loaded on the node"},
+		{fmt.Sprintf("ip link set dev %v up", ifbDevice), "failed to set up the ifb device"},
+		{fmt.Sprintf("tc qdisc replace dev %v handle ffff: ingress", netInterface), "failed to create the ingress qdisc"},
+		{fmt.Sprintf("tc filter add dev %v parent ffff: protocol all u32 match u32 0 0 action mirred egress redirect dev %v", netInterface, ifbDevice), "failed to redirect the ingress traffic to the ifb device"},
+	}
+	for _, c := range commands {
+		tc := fmt.Sprintf("sudo nsenter -t %v -n %v", target.Pid, c.command)
+		log.Info(tc)
+		if err := common.RunBashCommand(tc, c.failMsg, target.Source); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		for _, port := range dPorts {
-			//redirect traffic to specific dport through band 3
-			tc := fmt.Sprintf("sudo nsenter -t %v -n tc filter add dev %v protocol ip parent 1:0 prio 3 u32 match ip dport %v 0xffff flowid 1:3", target.Pid, netInterface, port)
-			log.Info(tc)
-			if err := common.RunBashCommand(tc, "failed to create destination ports match filters", target.Source); err != nil {
-				return err
+// removeStaleIngress removes the ingress qdisc and the ifb device left behind by the previous run, if any
+// the redirect filters are removed along with the ingress qdisc
+func removeStaleIngress(netInterface string, target targetDetails) error {
+	commands := []struct {
+		command, notFound string
+	}{
+		{fmt.Sprintf("tc qdisc delete dev %v ingress", netInterface), qdiscInvalidArgument},
+		{fmt.Sprintf("ip link delete %v", ifbDevice), deviceNotFound},
+	}
+	for _, c := range commands {
+		tc := fmt.Sprintf("sudo nsenter -t %v -n %v", target.Pid, c.command)
+		out, err := exec.Command("/bin/bash", "-c", tc).CombinedOutput()
+		if err != nil {
+			if strings.Contains(string(out), c.notFound) || strings.Contains(string(out), qdiscNoFileFound) {
+				continue
 			}
+			log.Error(err.Error())
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Source: target.Source, Target: fmt.Sprintf("{podName: %s, namespace: %s, container: %s}", target.Name, target.Namespace, target.TargetContainer), Reason: fmt.Sprintf("failed to remove the stale ingress redirection: %s", string(out))}
 		}
+		log.Infof("removed the stale ingress redirection: %v", tc)
 	}
-
-	log.Infof("chaos injected successfully on {pod: %v, container: %v}", target.Name, target.TargetContainer)
 	return nil
 }
 
 // killnetem kill the netem process for all the target containers
+// in ingress direction, it removes the ingress qdisc and the ifb device
 func killnetem(target targetDetails, networkInterface string) (bool, error) {
 
-	tc := fmt.Sprintf("sudo nsenter -t %d -n tc qdisc delete dev %s root", target.Pid, networkInterface)
+	if direction != "ingress" {
+		return revertChaos(target, fmt.Sprintf("tc qdisc delete dev %s root", networkInterface))
+	}
+
+	// the qdisc of the ifb device is removed along with the device
+	killed, err := revertChaos(target, fmt.Sprintf("tc qdisc delete dev %s ingress", networkInterface))
+	if !killed && err != nil {
+		return killed, err
+	}
+	return revertChaos(target, fmt.Sprintf("ip link delete %s", ifbDevice))
+}
+
+// revertChaos runs the given revert command inside the network namespace of the target container
+// it returns true, if the chaos is reverted or it has been already removed
+func revertChaos(target targetDetails, command string) (bool, error) {
+
+	tc := fmt.Sprintf("sudo nsenter -t %d -n %s", target.Pid, command)
 	cmd := exec.Command("/bin/bash", "-c", tc)
 	out, err := cmd.CombinedOutput()
 
 	if err != nil {
 		log.Info(cmd.String())
 		// ignoring err if qdisc process doesn't exist inside the target container
-		if strings.Contains(string(out), qdiscNotFound) || strings.Contains(string(out), qdiscNoFileFound) {
+		if strings.Contains(string(out), qdiscNotFound) || strings.Contains(string(out), qdiscNoFileFound) || strings.Contains(string(out), qdiscInvalidArgument) || strings.Contains(string(out), deviceNotFound) {
 			log.Warn("The network chaos process has already been removed")
 			return true, err
 		}
@@ -277,6 +405,8 @@ type targetDetails struct {
 	ContainerId     string
 	Pid             int
 	Source          string
+	// Generation is the number of times the destination ips have been reconciled
+	Generation int
 }
 
 //getENV fetches all the env variables from the runner pod
@@ -291,11 +421,30 @@ func getENV(experimentDetails *experimentTypes.ExperimentDetails) {
 	experimentDetails.ContainerRuntime = types.Getenv("CONTAINER_RUNTIME", "")
 	experimentDetails.NetworkInterface = types.Getenv("NETWORK_INTERFACE", "")
 	experimentDetails.SocketPath = types.Getenv("SOCKET_PATH", "")
-	experimentDetails.DestinationIPs = types.Getenv("DESTINATION_IPS", "")
+	experimentDetails.DestinationIPs = types.Getenv("STATIC_DESTINATION_IPS", "")
 	experimentDetails.SourcePorts = types.Getenv("SOURCE_PORTS", "")
 	experimentDetails.DestinationPorts = types.Getenv("DESTINATION_PORTS", "")
+	experimentDetails.FaultVerification = types.Getenv("FAULT_VERIFICATION", "true")
+	experimentDetails.VerificationRTTTarget = types.Getenv("VERIFICATION_RTT_TARGET", "")
+	experimentDetails.Qdisc = types.Getenv("QDISC", "netem")
+	experimentDetails.TrafficDirection = types.Getenv("TRAFFIC_DIRECTION", "egress")
+	experimentDetails.DestinationHosts = types.Getenv("DESTINATION_HOSTS", "")
+	experimentDetails.DestinationRefreshInterval, _ = strconv.Atoi(types.Getenv("DESTINATION_REFRESH_INTERVAL", "0"))
+	experimentDetails.Schedule = types.Getenv("NETWORK_CHAOS_SCHEDULE", "")
+	experimentDetails.NetworkProtocol = types.Getenv("NETWORK_PROTOCOL", "all")
+	experimentDetails.ExcludedIPs = types.Getenv("EXCLUDED_IPS", "")
+	experimentDetails.ExcludedPorts = types.Getenv("EXCLUDED_PORTS", "")
+
+	qdisc = experimentDetails.Qdisc
+	targetType = types.Getenv("TARGET_TYPE", "pod")
+	direction = strings.ToLower(experimentDetails.TrafficDirection)
+	protocol = strings.ToLower(experimentDetails.NetworkProtocol)
+	refreshEnabled = experimentDetails.DestinationRefreshInterval > 0 && strings.TrimSpace(experimentDetails.DestinationHosts) != ""
+	excludedIps = getDestinationIPs(experimentDetails.ExcludedIPs)
+	if strings.TrimSpace(experimentDetails.ExcludedPorts) != "" {
+		excludedPorts = strings.Split(strings.TrimSpace(experimentDetails.ExcludedPorts), ",")
+	}
 
-	destIps = getDestinationIPs(experimentDetails.DestinationIPs)
 	if strings.TrimSpace(experimentDetails.DestinationPorts) != "" {
 		dPorts = strings.Split(strings.TrimSpace(experimentDetails.DestinationPorts), ",")
 	}
@@ -320,11 +469,24 @@ func getDestinationIPs(ips string) []string {
 	return uniqueIps
 }
 
+// getNodeTarget returns the target details of the node
+// the helper pod shares the host pid namespace, so the network namespace of the init process is the host network namespace
+func getNodeTarget(nodeName, source string) targetDetails {
+	return targetDetails{
+		Name:           nodeName,
+		ServiceMesh:    "false",
+		DestinationIps: getDestIps("false"),
+		Pid:            1,
+		Source:         source,
+	}
+}
+
 // abortWatcher continuously watch for the abort signals
-func abortWatcher(targets []targetDetails, networkInterface, resultName, chaosNS string) {
+func abortWatcher(targets []targetDetails, networkInterface, resultName, chaosNS string, scheduler *scheduleRunner) {
 
 	<-abort
 	log.Info("[Chaos]: Killing process started because of terminated signal received")
+	scheduler.halt()
 	log.Info("Chaos Revert Started")
 	// retry thrice for the chaos revert
 	retry := 3
@@ -336,7 +498,7 @@ func abortWatcher(targets []targetDetails, networkInterface, resultName, chaosNS
 				continue
 			}
 			if killed && err == nil {
-				if err = result.AnnotateChaosResult(resultName, chaosNS, "reverted", "pod", t.Name); err != nil {
+				if err = result.AnnotateChaosResult(resultName, chaosNS, "reverted", targetType, t.Name); err != nil {
 					log.Errorf("unable to annotate the chaosresult, err :%v", err)
 				}
 			}
@@ -0,0 +1,199 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/network-chaos/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/common"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// refreshDestinations re-resolves the destination hosts at every refresh interval till the end of the chaos duration
+// and reconciles the destination ip filters of the targets, if the resolved ips are changed
+func refreshDestinations(experimentsDetails *experimentTypes.ExperimentDetails, targets []targetDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) {
+	endTime := time.Now().Add(time.Duration(experimentsDetails.ChaosDuration) * time.Second)
+	interval := time.Duration(experimentsDetails.DestinationRefreshInterval) * time.Second
+	log.Infof("[Chaos]: Refreshing the destinations at every %vs", experimentsDetails.DestinationRefreshInterval)
+
+	// resolving the hosts once before the chaos, so that the hosts failing to resolve in a refresh keep their ips
+	hosts := resolvedHosts{}
+	for i := range targets {
+		if _, err := resolveDestinations(experimentsDetails, targets[i].ServiceMesh == "true", clients, hosts); err != nil {
+			log.Warnf("[Refresh]: Unable to resolve the destinations of target: {name: %s, namespace: %v}, err: %v", targets[i].Name, targets[i].Namespace, err)
+		}
+	}
+
+	for {
+		remaining := time.Until(endTime)
+		if remaining <= 0 {
+			return
+		}
+		if remaining < interval {
+			time.Sleep(remaining)
+			return
+		}
+		time.Sleep(interval)
+
+		for i := range targets {
+			ips, err := resolveDestinations(experimentsDetails, targets[i].ServiceMesh == "true", clients, hosts)
+			if err != nil {
+				log.Warnf("[Refresh]: Unable to refresh the destinations of target: {name: %s, namespace: %v}, keeping the existing filters, err: %v", targets[i].Name, targets[i].Namespace, err)
+				continue
+			}
+			added, removed, err := reconcileDestinations(experimentsDetails.NetworkInterface, &targets[i], ips)
+			if err != nil {
+				log.Errorf("[Refresh]: Unable to reconcile the destinations of target: {name: %s, namespace: %v}, err: %v", targets[i].Name, targets[i].Namespace, err)
+				recordChaosEvent(experimentsDetails, clients, chaosDetails, fmt.Sprintf("unable to reconcile the destinations of %v pod: %v", targets[i].Name, err), "Warning")
+				continue
+			}
+			if len(added) == 0 && len(removed) == 0 {
+				continue
+			}
+			msg := fmt.Sprintf("destinations of %v pod are updated, added: %v, removed: %v", targets[i].Name, added, removed)
+			log.Infof("[Refresh]: The %v", msg)
+			recordChaosEvent(experimentsDetails, clients, chaosDetails, msg, "Normal")
+		}
+	}
+}
+
+// resolvedHosts contains the last resolved ips of the destination hosts
+// it is keyed by the host along with the service mesh flag, as the services are resolved differently for the service mesh enabled targets
+type resolvedHosts map[string][]string
+
+// resolveDestinations resolves the destination ips from the static ips and the destination hosts
+// the services are resolved to the endpoints for the service mesh enabled targets, rest of the hosts are resolved via dns
+// the previously resolved ips of the host are kept, if the host fails to resolve
+func resolveDestinations(experimentsDetails *experimentTypes.ExperimentDetails, serviceMesh bool, clients clients.ClientSets, hosts resolvedHosts) ([]string, error) {
+	ips := getDestinationIPs(experimentsDetails.DestinationIPs)
+
+	for _, host := range strings.Split(experimentsDetails.DestinationHosts, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		key := fmt.Sprintf("%v/%v", serviceMesh, host)
+		if serviceMesh && strings.Contains(host, "svc.cluster.local") {
+			endpointIps, err := getEndpointIPs(host, clients)
+			if err != nil {
+				previous, ok := hosts[key]
+				if !ok {
+					return nil, err
+				}
+				log.Warnf("[Refresh]: Unable to get the endpoints of the host: {%v}, keeping the previously resolved ips: %v, err: %v", host, previous, err)
+				endpointIps = previous
+			}
+			hosts[key] = endpointIps
+			ips = append(ips, endpointIps...)
+			continue
+		}
+		lookupIps, err := net.LookupIP(host)
+		if err != nil {
+			previous, ok := hosts[key]
+			if !ok {
+				log.Warnf("[Refresh]: Unable to resolve the host: {%v}, err: %v", host, err)
+				continue
+			}
+			log.Warnf("[Refresh]: Unable to resolve the host: {%v}, keeping the previously resolved ips: %v, err: %v", host, previous, err)
+			ips = append(ips, previous...)
+			continue
+		}
+		var hostIps []string
+		for _, ip := range lookupIps {
+			hostIps = append(hostIps, ip.String())
+		}
+		hosts[key] = hostIps
+		ips = append(ips, hostIps...)
+	}
+
+	ips = getDestinationIPs(strings.Join(ips, ","))
+	if len(ips) == 0 {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeHelper, Target: fmt.Sprintf("{hosts: %s}", experimentsDetails.DestinationHosts), Reason: "none of the destinations are resolved"}
+	}
+	return ips, nil
+}
+
+// getEndpointIPs returns the ips of the ready endpoints of the service
+func getEndpointIPs(host string, clients clients.ClientSets) ([]string, error) {
+	svcFields := strings.Split(host, ".")
+	if len(svcFields) != 5 {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeHelper, Target: fmt.Sprintf("{host: %s}", host), Reason: "provide the valid FQDN for service in '<svc-name>.<namespace>.svc.cluster.local format"}
+	}
+	svcName, svcNs := svcFields[0], svcFields[1]
+	endpoints, err := clients.KubeClient.CoreV1().Endpoints(svcNs).Get(context.Background(), svcName, v1.GetOptions{})
+	if err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeHelper, Target: fmt.Sprintf("{serviceName: %s, namespace: %s}", svcName, svcNs), Reason: fmt.Sprintf("failed to get the endpoints: %s", err.Error())}
+	}
+
+	var ips []string
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			ips = append(ips, address.IP)
+		}
+	}
+	return ips, nil
+}
+
+// reconcileDestinations updates the destination ip filters of the target, if the resolved ips are changed
+// the filters of the resolved ips are added with the priority of the next generation, before removing the filters
+// of the current generation, so that the chaos is applied on the destinations throughout the reconciliation
+func reconcileDestinations(netInterface string, target *targetDetails, ips []string) ([]string, []string, error) {
+	current := getDestinationIPs(target.DestinationIps)
+	added, removed := difference(ips, current), difference(current, ips)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil, nil, nil
+	}
+
+	device, ipMatch := getChaosDevice(netInterface)
+	generation := target.Generation + 1
+	for _, filter := range getDestinationFilters(ipMatch, ips, generation) {
+		tc := filter.command(target.Pid, device)
+		log.Info(tc)
+		if err := common.RunBashCommand(tc, "failed to create destination ips match filters", target.Source); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, family := range []addressFamily{ipv4, ipv6} {
+		tc := fmt.Sprintf("sudo nsenter -t %v -n tc filter del dev %v parent 1:0 protocol %v prio %v", target.Pid, device, family.protocol, family.destinationPrios[target.Generation%2])
+		log.Info(tc)
+		// the family may not have any filter of the current generation
+		if err := common.RunBashCommand(tc, "failed to delete stale destination ips match filters", target.Source); err != nil {
+			log.Warnf("[Refresh]: %v", err)
+		}
+	}
+
+	// the target is injected without any destination, so all the traffic of the selected protocol is redirected to the chaos band
+	// these filters are removed once the destinations are resolved, otherwise the chaos isn't limited to the destinations
+	if len(current) == 0 && len(sPorts) == 0 && len(dPorts) == 0 {
+		for _, filter := range getMatchAllFilters() {
+			tc := fmt.Sprintf("sudo nsenter -t %v -n tc filter del dev %v parent 1:0 protocol %v prio %v", target.Pid, device, filter.protocol, filter.prio)
+			log.Info(tc)
+			if err := common.RunBashCommand(tc, "failed to delete the match all filters", target.Source); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	target.DestinationIps = strings.Join(ips, ",")
+	target.Generation = generation
+	return added, removed, nil
+}
+
+// difference returns the values of a, which are not present in b
+func difference(a, b []string) []string {
+	var diff []string
+	for _, v := range a {
+		if !common.Contains(v, b) {
+			diff = append(diff, v)
+		}
+	}
+	return diff
+}
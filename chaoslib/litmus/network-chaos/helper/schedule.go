@@ -0,0 +1,232 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/network-chaos/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/common"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// defaultRampInterval is the default interval between the latency changes of the ramp, in seconds
+const defaultRampInterval = 5
+
+// resetKeywords are the netem options, which are retained by the qdisc change if they are not provided
+// so the qdisc is recreated, if any of these options is removed during the transition
+var resetKeywords = []string{"corrupt", "reorder", "rate", "slot"}
+
+// scheduledTarget contains the current state of the chaos qdisc of the target
+type scheduledTarget struct {
+	target targetDetails
+	// position is the position of the chaos qdisc, it is the root or the 1:3 band of the prio qdisc
+	position string
+	args     string
+	active   bool
+}
+
+// scheduleRunner runs the schedule in the background
+// it is halted before reverting the chaos, so that the schedule doesn't re-add the chaos qdisc after the revert
+type scheduleRunner struct {
+	stop chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// newScheduleRunner creates the runner of the schedule
+func newScheduleRunner() *scheduleRunner {
+	return &scheduleRunner{stop: make(chan struct{})}
+}
+
+// start runs the schedule in the background, till it completes or the runner is halted
+func (r *scheduleRunner) start(run func(stop <-chan struct{})) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		run(r.stop)
+	}()
+}
+
+// halt stops the schedule and waits for the in-progress transition to complete
+func (r *scheduleRunner) halt() {
+	r.once.Do(func() { close(r.stop) })
+	r.wg.Wait()
+}
+
+// getSchedule parses the schedule of the chaos, it returns nil if the schedule is not provided
+func getSchedule(experimentsDetails *experimentTypes.ExperimentDetails) (*experimentTypes.Schedule, error) {
+	if strings.TrimSpace(experimentsDetails.Schedule) == "" {
+		return nil, nil
+	}
+	var schedule experimentTypes.Schedule
+	if err := sigsyaml.Unmarshal([]byte(experimentsDetails.Schedule), &schedule); err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeHelper, Source: experimentsDetails.ChaosPodName, Reason: fmt.Sprintf("unable to parse the schedule, err: %v", err)}
+	}
+	schedule.Type = strings.ToLower(schedule.Type)
+	return &schedule, nil
+}
+
+// runSchedule changes the chaos qdisc of all the targets in place, as per the schedule till the end of the chaos duration
+// every transition is logged and recorded as the event inside the chaosengine
+// it returns early, once the stop channel is closed
+func runSchedule(stop <-chan struct{}, experimentsDetails *experimentTypes.ExperimentDetails, schedule *experimentTypes.Schedule, targets []targetDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) {
+	start := time.Now()
+	endTime := start.Add(time.Duration(experimentsDetails.ChaosDuration) * time.Second)
+	netemCommands := os.Getenv("NETEM_COMMAND")
+
+	var scheduledTargets []*scheduledTarget
+	for _, t := range targets {
+		position := "root"
+		if isFilterRequired(t) {
+			position = "parent 1:3"
+		}
+		scheduledTargets = append(scheduledTargets, &scheduledTarget{target: t, position: position, args: netemCommands, active: true})
+	}
+
+	transition := func(args string, active bool, description string) {
+		log.Infof("[Schedule]: %v", description)
+		for _, t := range scheduledTargets {
+			if err := t.apply(experimentsDetails.NetworkInterface, args, active); err != nil {
+				log.Errorf("[Schedule]: Unable to apply the transition on target: {name: %s, namespace: %v}, err: %v", t.target.Name, t.target.Namespace, err)
+				recordChaosEvent(experimentsDetails, clients, chaosDetails, fmt.Sprintf("unable to apply the schedule transition on %v pod: %v", t.target.Name, err), "Warning")
+			}
+		}
+		recordChaosEvent(experimentsDetails, clients, chaosDetails, description, "Normal")
+	}
+
+	switch schedule.Type {
+	case "ramp":
+		duration := schedule.Ramp.Duration
+		if duration <= 0 {
+			duration = experimentsDetails.ChaosDuration
+		}
+		interval := schedule.Ramp.Interval
+		if interval <= 0 {
+			interval = defaultRampInterval
+		}
+		for elapsed := 0; ; elapsed += interval {
+			if elapsed > duration {
+				elapsed = duration
+			}
+			if !waitUntil(stop, start.Add(time.Duration(elapsed)*time.Second), endTime) {
+				return
+			}
+			latency := schedule.Ramp.From
+			if duration > 0 {
+				latency += (schedule.Ramp.To - schedule.Ramp.From) * elapsed / duration
+			}
+			transition(getRampArgs(netemCommands, latency), true, fmt.Sprintf("latency is ramped to %vms", latency))
+			if elapsed >= duration {
+				return
+			}
+		}
+	case "steps":
+		at := start
+		for i, step := range schedule.Steps {
+			if !waitUntil(stop, at, endTime) {
+				return
+			}
+			transition(step.Args, true, fmt.Sprintf("step %v is applied with '%v' for %vs", i+1, step.Args, step.Duration))
+			at = at.Add(time.Duration(step.Duration) * time.Second)
+		}
+	case "flap":
+		at := start
+		for {
+			at = at.Add(time.Duration(schedule.Flap.OnPeriod) * time.Second)
+			if !waitUntil(stop, at, endTime) {
+				return
+			}
+			transition(netemCommands, false, fmt.Sprintf("chaos is flapped off for %vs", schedule.Flap.OffPeriod))
+			at = at.Add(time.Duration(schedule.Flap.OffPeriod) * time.Second)
+			if !waitUntil(stop, at, endTime) {
+				return
+			}
+			transition(netemCommands, true, fmt.Sprintf("chaos is flapped on for %vs", schedule.Flap.OnPeriod))
+		}
+	}
+}
+
+// apply changes the chaos qdisc of the target in place
+// the qdisc is removed from its position to turn off the chaos and added back to turn on the chaos
+func (t *scheduledTarget) apply(netInterface, args string, active bool) error {
+	device, _ := getChaosDevice(netInterface)
+	prefix := fmt.Sprintf("sudo nsenter -t %v -n tc qdisc", t.target.Pid)
+
+	var commands []string
+	switch {
+	case !active:
+		if t.active {
+			commands = append(commands, fmt.Sprintf("%v delete dev %v %v", prefix, device, t.position))
+		}
+	case !t.active:
+		commands = append(commands, fmt.Sprintf("%v replace dev %v %v %v %v", prefix, device, t.position, qdisc, args))
+	case requiresRecreate(t.args, args):
+		commands = append(commands, fmt.Sprintf("%v delete dev %v %v", prefix, device, t.position), fmt.Sprintf("%v replace dev %v %v %v %v", prefix, device, t.position, qdisc, args))
+	default:
+		commands = append(commands, fmt.Sprintf("%v change dev %v %v %v %v", prefix, device, t.position, qdisc, args))
+	}
+
+	for _, tc := range commands {
+		log.Info(tc)
+		if err := common.RunBashCommand(tc, "failed to change the chaos qdisc", t.target.Source); err != nil {
+			return err
+		}
+	}
+	t.args, t.active = args, active
+	return nil
+}
+
+// requiresRecreate checks whether the qdisc needs to be recreated for the transition
+// netem retains some of the options, if they are not provided during the change
+func requiresRecreate(current, next string) bool {
+	if qdisc != "netem" {
+		return false
+	}
+	for _, keyword := range resetKeywords {
+		if containsOption(current, keyword) && !containsOption(next, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsOption checks whether the qdisc arguments contain the given option
+func containsOption(args, option string) bool {
+	for _, field := range strings.Fields(args) {
+		if field == option {
+			return true
+		}
+	}
+	return false
+}
+
+// getRampArgs replaces the delay inside the netem command with the given latency
+// the delay is added, if the netem command doesn't contain it
+func getRampArgs(netemCommands string, latency int) string {
+	if delayRegex.MatchString(netemCommands) {
+		return delayRegex.ReplaceAllString(netemCommands, fmt.Sprintf("delay %vms", latency))
+	}
+	return strings.TrimSpace(fmt.Sprintf("delay %vms %v", latency, netemCommands))
+}
+
+// waitUntil waits till the given time, it returns false if the given time is after the end of the chaos or the schedule is stopped
+func waitUntil(stop <-chan struct{}, at, endTime time.Time) bool {
+	if !at.Before(endTime) {
+		return false
+	}
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,198 @@
+package helper
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+var (
+	// rttRegex extracts the min/avg/max rtt from the ping summary
+	rttRegex = regexp.MustCompile(`= ([\d.]+)/([\d.]+)/([\d.]+)`)
+	// delayRegex extracts the delay along with its unit from the netem command, tc considers the delay without unit in usec
+	delayRegex = regexp.MustCompile(`delay (\d+(?:\.\d+)?)(s|secs?|ms|msecs?|us|usecs?)?\b`)
+	// jitterRegex extracts the jitter along with its unit, which follows the delay inside the netem command
+	jitterRegex = regexp.MustCompile(`delay \d+(?:\.\d+)?(?:s|secs?|ms|msecs?|us|usecs?)?\s+(\d+(?:\.\d+)?)(s|secs?|ms|msecs?|us|usecs?)?\b`)
+)
+
+// verifyChaos verifies that the chaos qdisc rules are present inside the network namespace of the target container
+// it also measures the rtt to the verification target, if provided
+func verifyChaos(netInterface, rttTarget string, target targetDetails) error {
+	log.Infof("[Verification]: Verifying the network chaos on target: {name: %s, namespace: %v, container: %v}", target.Name, target.Namespace, target.TargetContainer)
+
+	device, _ := getChaosDevice(netInterface)
+	if direction == "ingress" {
+		ingress, err := runInNetNS(target, fmt.Sprintf("tc filter show dev %s parent ffff:", netInterface))
+		if err != nil {
+			return verificationError(target, fmt.Sprintf("unable to list the ingress filters: %s", err.Error()))
+		}
+		if !strings.Contains(ingress, ifbDevice) {
+			return verificationError(target, fmt.Sprintf("ingress traffic of %s interface is not redirected to the %s device", netInterface, ifbDevice))
+		}
+	}
+
+	qdiscs, err := runInNetNS(target, fmt.Sprintf("tc qdisc show dev %s", device))
+	if err != nil {
+		return verificationError(target, fmt.Sprintf("unable to list the qdisc: %s", err.Error()))
+	}
+	if !strings.Contains(qdiscs, "qdisc "+qdisc+" ") {
+		return verificationError(target, fmt.Sprintf("%s qdisc is not found on %s interface, qdisc: %s", qdisc, device, strings.TrimSpace(qdiscs)))
+	}
+
+	if isFilterRequired(target) {
+		if !strings.Contains(qdiscs, "prio 1:") {
+			return verificationError(target, fmt.Sprintf("prio qdisc is not found on %s interface, qdisc: %s", device, strings.TrimSpace(qdiscs)))
+		}
+		filters, err := runInNetNS(target, fmt.Sprintf("tc filter show dev %s parent 1:0", device))
+		if err != nil {
+			return verificationError(target, fmt.Sprintf("unable to list the filters: %s", err.Error()))
+		}
+		if !strings.Contains(filters, "flowid 1:3") {
+			return verificationError(target, fmt.Sprintf("no filter is redirecting the traffic to the %s band on %s interface", qdisc, device))
+		}
+	}
+
+	if rttTarget != "" {
+		if err := verifyRTT(rttTarget, target); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("[Verification]: The network chaos is verified on target: {name: %s, namespace: %v, container: %v}", target.Name, target.Namespace, target.TargetContainer)
+	return nil
+}
+
+// verifyRTT measures the rtt to the verification target from the network namespace of the target container
+// the max rtt should be at least the injected delay minus the jitter, if the netem command contains the delay
+// it is skipped, if the ping traffic to the verification target is not affected by the chaos filters
+// it fails, if the rtt can't be measured for a reason other than the packet loss
+func verifyRTT(rttTarget string, target targetDetails) error {
+	if reason := getUncoveredReason(rttTarget, target); reason != "" {
+		log.Warnf("[Verification]: Skipping the rtt verification, as %s is not affected by the chaos: %s", rttTarget, reason)
+		return nil
+	}
+
+	out, err := runInNetNS(target, fmt.Sprintf("ping -c 3 -W 2 %s", rttTarget))
+	match := rttRegex.FindStringSubmatch(out)
+	if match == nil {
+		// the ping prints the packet loss summary, if the target doesn't respond while the packets are being dropped
+		if err != nil && !strings.Contains(out, "packet loss") {
+			return verificationError(target, fmt.Sprintf("unable to measure the rtt to %s: %s", rttTarget, err.Error()))
+		}
+		log.Warnf("[Verification]: Unable to measure the rtt to %s, output: %s", rttTarget, strings.TrimSpace(out))
+		return nil
+	}
+	maxRTT, _ := strconv.ParseFloat(match[3], 64)
+	log.Infof("[Verification]: The max rtt to %s is %vms", rttTarget, maxRTT)
+
+	expected, ok := getDelay(os.Getenv("NETEM_COMMAND"))
+	if !ok {
+		return nil
+	}
+	if maxRTT < expected {
+		return verificationError(target, fmt.Sprintf("max rtt to %s is %vms, which is less than the minimum injected delay of %vms", rttTarget, maxRTT, expected))
+	}
+	return nil
+}
+
+// getDelay returns the minimum delay of the netem command in ms, which is the delay minus the jitter
+func getDelay(netemCommands string) (float64, bool) {
+	match := delayRegex.FindStringSubmatch(netemCommands)
+	if match == nil {
+		return 0, false
+	}
+	delay, ok := toMilliseconds(match[1], match[2])
+	if !ok {
+		return 0, false
+	}
+	if match = jitterRegex.FindStringSubmatch(netemCommands); match != nil {
+		if jitter, ok := toMilliseconds(match[1], match[2]); ok {
+			delay = math.Max(0, delay-jitter)
+		}
+	}
+	return delay, true
+}
+
+// toMilliseconds converts the time of the netem command into ms, tc considers the time without unit in usec
+func toMilliseconds(value, unit string) (float64, bool) {
+	t, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	switch unit {
+	case "s", "sec", "secs":
+		return t * 1000, true
+	case "ms", "msec", "msecs":
+		return t, true
+	default:
+		return t / 1000, true
+	}
+}
+
+// getUncoveredReason checks whether the ping traffic to the verification target is affected by the chaos filters
+// it returns the reason, if the verification target is not covered by the filters
+func getUncoveredReason(rttTarget string, target targetDetails) string {
+	if protocol != "all" && protocol != "icmp" {
+		return fmt.Sprintf("only the %s traffic is targeted", protocol)
+	}
+	if len(sPorts) != 0 || len(dPorts) != 0 {
+		return "only the traffic of the source or destination ports is targeted"
+	}
+
+	ips, err := resolveRTTTarget(rttTarget)
+	if err != nil {
+		return fmt.Sprintf("unable to resolve the verification target, err: %v", err)
+	}
+	if containsAnyIP(excludedIps, ips) {
+		return "it is excluded from the chaos"
+	}
+	if destinationIps := getDestinationIPs(target.DestinationIps); len(destinationIps) != 0 && !containsAnyIP(destinationIps, ips) {
+		return "it is not one of the destination ips"
+	}
+	return ""
+}
+
+// resolveRTTTarget returns the ips of the verification target
+func resolveRTTTarget(rttTarget string) ([]net.IP, error) {
+	if ip := net.ParseIP(rttTarget); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	return net.LookupIP(rttTarget)
+}
+
+// containsAnyIP checks whether any of the ips is present inside the list of ips or cidrs
+func containsAnyIP(list []string, ips []net.IP) bool {
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		_, cidr, err := net.ParseCIDR(entry)
+		for _, ip := range ips {
+			if (err == nil && cidr.Contains(ip)) || ip.Equal(net.ParseIP(entry)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runInNetNS runs the given command inside the network namespace of the target container
+func runInNetNS(target targetDetails, command string) (string, error) {
+	cmd := exec.Command("/bin/bash", "-c", fmt.Sprintf("sudo nsenter -t %d -n %s", target.Pid, command))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// verificationError returns the chaos inject error for the failed verification
+func verificationError(target targetDetails, reason string) error {
+	return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Source: target.Source, Target: fmt.Sprintf("{podName: %s, namespace: %s, container: %s}", target.Name, target.Namespace, target.TargetContainer), Reason: fmt.Sprintf("fault verification failed: %s", reason)}
+}
@@ -0,0 +1,97 @@
+package degradation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	network_chaos "github.com/litmuschaos/litmus-go/chaoslib/litmus/network-chaos/lib"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/network-chaos/types"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+)
+
+// jitterDistributions contains the delay distributions supported by netem
+var jitterDistributions = []string{"uniform", "normal", "pareto", "paretonormal"}
+
+// PodNetworkDegradationChaos contains the steps to prepare and inject chaos
+func PodNetworkDegradationChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails) error {
+
+	args, err := getDegradationArgs(experimentsDetails)
+	if err != nil {
+		return err
+	}
+	return network_chaos.PrepareAndInjectChaos(experimentsDetails, clients, resultDetails, eventsDetails, chaosDetails, args)
+}
+
+// getDegradationArgs derives the netem command, which combines all the provided faults in a single qdisc
+// the faults with zero values are skipped
+func getDegradationArgs(experimentsDetails *experimentTypes.ExperimentDetails) (string, error) {
+	var args []string
+
+	if experimentsDetails.NetworkLatency > 0 {
+		delay := fmt.Sprintf("delay %vms", experimentsDetails.NetworkLatency)
+		if experimentsDetails.Jitter > 0 {
+			delay += fmt.Sprintf(" %vms", experimentsDetails.Jitter)
+			if experimentsDetails.JitterDistribution != "" {
+				distribution := strings.ToLower(experimentsDetails.JitterDistribution)
+				if !isSupportedDistribution(distribution) {
+					return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' jitter distribution is not supported, it should be one of %v", experimentsDetails.JitterDistribution, jitterDistributions)}
+				}
+				delay += " distribution " + distribution
+			}
+		}
+		args = append(args, delay)
+	}
+
+	if isPositive(experimentsDetails.NetworkPacketLossPercentage) {
+		args = append(args, fmt.Sprintf("loss %v %v", experimentsDetails.NetworkPacketLossPercentage, getCorrelation(experimentsDetails.LossCorrelation)))
+	}
+	if isPositive(experimentsDetails.NetworkPacketDuplicationPercentage) {
+		args = append(args, fmt.Sprintf("duplicate %v", experimentsDetails.NetworkPacketDuplicationPercentage))
+	}
+	if isPositive(experimentsDetails.NetworkPacketCorruptionPercentage) {
+		args = append(args, fmt.Sprintf("corrupt %v", experimentsDetails.NetworkPacketCorruptionPercentage))
+	}
+	if isPositive(experimentsDetails.NetworkPacketReorderPercentage) {
+		// netem reorders the packets only if the packets are delayed
+		if experimentsDetails.NetworkLatency <= 0 {
+			return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "provide the NETWORK_LATENCY greater than 0 along with the NETWORK_PACKET_REORDER_PERCENTAGE"}
+		}
+		reorder := fmt.Sprintf("reorder %v %v", experimentsDetails.NetworkPacketReorderPercentage, getCorrelation(experimentsDetails.ReorderCorrelation))
+		if experimentsDetails.ReorderGap > 0 {
+			reorder += fmt.Sprintf(" gap %v", experimentsDetails.ReorderGap)
+		}
+		args = append(args, reorder)
+	}
+
+	if len(args) == 0 {
+		return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "provide at least one of the latency, loss, duplication, corruption or reorder for the network degradation"}
+	}
+	return strings.Join(args, " "), nil
+}
+
+// isPositive checks whether the given percentage is greater than zero
+func isPositive(percentage string) bool {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(percentage), "%"), 64)
+	return err == nil && value > 0
+}
+
+// getCorrelation returns the correlation, it defaults to zero if it is not provided
+func getCorrelation(correlation string) string {
+	if strings.TrimSpace(correlation) == "" {
+		return "0"
+	}
+	return correlation
+}
+
+// isSupportedDistribution checks whether the given distribution is supported by netem
+func isSupportedDistribution(distribution string) bool {
+	for _, d := range jitterDistributions {
+		if d == distribution {
+			return true
+		}
+	}
+	return false
+}
@@ -15,3 +15,10 @@ func PodNetworkLatencyChaos(experimentsDetails *experimentTypes.ExperimentDetail
 	args := "delay " + strconv.Itoa(experimentsDetails.NetworkLatency) + "ms " + strconv.Itoa(experimentsDetails.Jitter) + "ms"
 	return network_chaos.PrepareAndInjectChaos(experimentsDetails, clients, resultDetails, eventsDetails, chaosDetails, args)
 }
+
+//NodeNetworkLatencyChaos contains the steps to prepare and inject chaos on the nodes
+func NodeNetworkLatencyChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails) error {
+
+	args := "delay " + strconv.Itoa(experimentsDetails.NetworkLatency) + "ms " + strconv.Itoa(experimentsDetails.Jitter) + "ms"
+	return network_chaos.PrepareAndInjectNodeChaos(experimentsDetails, clients, resultDetails, eventsDetails, chaosDetails, args)
+}
@@ -13,3 +13,10 @@ func PodNetworkLossChaos(experimentsDetails *experimentTypes.ExperimentDetails,
 	args := "loss " + experimentsDetails.NetworkPacketLossPercentage
 	return network_chaos.PrepareAndInjectChaos(experimentsDetails, clients, resultDetails, eventsDetails, chaosDetails, args)
 }
+
+//NodeNetworkLossChaos contains the steps to prepare and inject chaos on the nodes
+func NodeNetworkLossChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails) error {
+
+	args := "loss " + experimentsDetails.NetworkPacketLossPercentage
+	return network_chaos.PrepareAndInjectNodeChaos(experimentsDetails, clients, resultDetails, eventsDetails, chaosDetails, args)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/sirupsen/logrus"
 	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 var serviceMesh = []string{"istio", "envoy"}
@@ -37,6 +38,12 @@ func PrepareAndInjectChaos(experimentsDetails *experimentTypes.ExperimentDetails
 	if experimentsDetails.TargetPods == "" && chaosDetails.AppDetail == nil {
 		return cerrors.Error{ErrorCode: cerrors.ErrorTypeTargetSelection, Reason: "provide one of the appLabel or TARGET_PODS"}
 	}
+	if err = validateFilters(experimentsDetails); err != nil {
+		return stacktrace.Propagate(err, "could not validate the traffic filters")
+	}
+	if err = validateSchedule(experimentsDetails); err != nil {
+		return stacktrace.Propagate(err, "could not validate the chaos schedule")
+	}
 	//set up the tunables if provided in range
 	SetChaosTunables(experimentsDetails)
 	logExperimentFields(experimentsDetails)
@@ -277,12 +284,22 @@ func getPodEnv(experimentsDetails *experimentTypes.ExperimentDetails, targets st
 
 	var envDetails common.ENVDetails
 	envDetails.SetEnv("TARGETS", targets).
+		SetEnv("TARGET_TYPE", experimentsDetails.TargetType).
 		SetEnv("TOTAL_CHAOS_DURATION", strconv.Itoa(experimentsDetails.ChaosDuration)).
 		SetEnv("CHAOS_NAMESPACE", experimentsDetails.ChaosNamespace).
 		SetEnv("CHAOSENGINE", experimentsDetails.EngineName).
 		SetEnv("CHAOS_UID", string(experimentsDetails.ChaosUID)).
 		SetEnv("CONTAINER_RUNTIME", experimentsDetails.ContainerRuntime).
 		SetEnv("NETEM_COMMAND", args).
+		SetEnv("QDISC", experimentsDetails.Qdisc).
+		SetEnv("TRAFFIC_DIRECTION", experimentsDetails.TrafficDirection).
+		SetEnv("NETWORK_PROTOCOL", experimentsDetails.NetworkProtocol).
+		SetEnv("EXCLUDED_IPS", experimentsDetails.ExcludedIPs).
+		SetEnv("EXCLUDED_PORTS", experimentsDetails.ExcludedPorts).
+		SetEnv("STATIC_DESTINATION_IPS", experimentsDetails.DestinationIPs).
+		SetEnv("DESTINATION_HOSTS", experimentsDetails.DestinationHosts).
+		SetEnv("DESTINATION_REFRESH_INTERVAL", strconv.Itoa(experimentsDetails.DestinationRefreshInterval)).
+		SetEnv("NETWORK_CHAOS_SCHEDULE", experimentsDetails.Schedule).
 		SetEnv("NETWORK_INTERFACE", experimentsDetails.NetworkInterface).
 		SetEnv("EXPERIMENT_NAME", experimentsDetails.ExperimentName).
 		SetEnv("SOCKET_PATH", experimentsDetails.SocketPath).
@@ -291,11 +308,107 @@ func getPodEnv(experimentsDetails *experimentTypes.ExperimentDetails, targets st
 		SetEnv("DESTINATION_IPS_SERVICE_MESH", destIpsSvcMesh).
 		SetEnv("SOURCE_PORTS", experimentsDetails.SourcePorts).
 		SetEnv("DESTINATION_PORTS", experimentsDetails.DestinationPorts).
+		SetEnv("FAULT_VERIFICATION", experimentsDetails.FaultVerification).
+		SetEnv("VERIFICATION_RTT_TARGET", experimentsDetails.VerificationRTTTarget).
 		SetEnvFromDownwardAPI("v1", "metadata.name")
 
 	return envDetails.ENV
 }
 
+// validateFilters validates the direction, protocol, ips and ports used to filter the traffic
+// the destination and excluded ips can be the ips or the cidrs of both the families
+func validateFilters(experimentsDetails *experimentTypes.ExperimentDetails) error {
+	switch strings.ToLower(experimentsDetails.TrafficDirection) {
+	case "egress", "ingress":
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' traffic direction is not supported, it should be one of egress or ingress", experimentsDetails.TrafficDirection)}
+	}
+
+	protocol := strings.ToLower(experimentsDetails.NetworkProtocol)
+	switch protocol {
+	case "tcp", "udp", "icmp", "all":
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' protocol is not supported, it should be one of tcp, udp, icmp or all", experimentsDetails.NetworkProtocol)}
+	}
+	if protocol == "icmp" && (experimentsDetails.SourcePorts != "" || experimentsDetails.DestinationPorts != "") {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "ports are not applicable for the icmp protocol"}
+	}
+
+	for _, ips := range []string{experimentsDetails.DestinationIPs, experimentsDetails.ExcludedIPs} {
+		if ips == "" {
+			continue
+		}
+		for _, ip := range strings.Split(ips, ",") {
+			if !isValidIPOrCIDR(strings.TrimSpace(ip)) {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' is neither a valid ip nor a valid cidr", ip)}
+			}
+		}
+	}
+
+	for _, ports := range []string{experimentsDetails.SourcePorts, experimentsDetails.DestinationPorts, experimentsDetails.ExcludedPorts} {
+		if ports == "" {
+			continue
+		}
+		for _, port := range strings.Split(ports, ",") {
+			if p, err := strconv.Atoi(strings.TrimSpace(port)); err != nil || p < 0 || p > 65535 {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' is not a valid port", port)}
+			}
+		}
+	}
+	return nil
+}
+
+// validateSchedule validates the schedule of the chaos, if provided
+func validateSchedule(experimentsDetails *experimentTypes.ExperimentDetails) error {
+	if strings.TrimSpace(experimentsDetails.Schedule) == "" {
+		return nil
+	}
+
+	var schedule experimentTypes.Schedule
+	if err := sigsyaml.Unmarshal([]byte(experimentsDetails.Schedule), &schedule); err != nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("unable to parse the schedule, err: %v", err)}
+	}
+
+	switch strings.ToLower(schedule.Type) {
+	case "ramp":
+		if schedule.Ramp == nil {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "provide the ramp details for the ramp schedule"}
+		}
+		if schedule.Ramp.From < 0 || schedule.Ramp.To < 0 || schedule.Ramp.Duration < 0 || schedule.Ramp.Interval < 0 {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "latency, duration and interval of the ramp schedule should not be negative"}
+		}
+		// the latency can be ramped only for the netem qdisc
+		if experimentsDetails.Qdisc != "" && experimentsDetails.Qdisc != "netem" {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("ramp schedule is not supported for the %v qdisc", experimentsDetails.Qdisc)}
+		}
+	case "steps":
+		if len(schedule.Steps) == 0 {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "provide at least one step for the steps schedule"}
+		}
+		for i, step := range schedule.Steps {
+			if step.Duration <= 0 || strings.TrimSpace(step.Args) == "" {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("provide the positive duration and the args for the step %v", i+1)}
+			}
+		}
+	case "flap":
+		if schedule.Flap == nil || schedule.Flap.OnPeriod <= 0 || schedule.Flap.OffPeriod <= 0 {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "provide the positive onPeriod and offPeriod for the flap schedule"}
+		}
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' schedule is not supported, it should be one of ramp, steps or flap", schedule.Type)}
+	}
+	return nil
+}
+
+// isValidIPOrCIDR checks whether the given value is an ip or a cidr
+func isValidIPOrCIDR(value string) bool {
+	if net.ParseIP(value) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
 type targetsDetails struct {
 	Target []target
 }
@@ -417,6 +530,7 @@ func SetChaosTunables(experimentsDetails *experimentTypes.ExperimentDetails) {
 	experimentsDetails.NetworkPacketLossPercentage = common.ValidateRange(experimentsDetails.NetworkPacketLossPercentage)
 	experimentsDetails.NetworkPacketCorruptionPercentage = common.ValidateRange(experimentsDetails.NetworkPacketCorruptionPercentage)
 	experimentsDetails.NetworkPacketDuplicationPercentage = common.ValidateRange(experimentsDetails.NetworkPacketDuplicationPercentage)
+	experimentsDetails.NetworkPacketReorderPercentage = common.ValidateRange(experimentsDetails.NetworkPacketReorderPercentage)
 	experimentsDetails.PodsAffectedPerc = common.ValidateRange(experimentsDetails.PodsAffectedPerc)
 	experimentsDetails.Sequence = common.GetRandomSequence(experimentsDetails.Sequence)
 }
@@ -510,5 +624,38 @@ func logExperimentFields(experimentsDetails *experimentTypes.ExperimentDetails)
 			"Sequence":                           experimentsDetails.Sequence,
 			"PodsAffectedPerc":                   experimentsDetails.PodsAffectedPerc,
 		})
+	case "network-rate-limit":
+		log.InfoWithValues("[Info]: The chaos tunables are:", logrus.Fields{
+			"NetworkBandwidth":    experimentsDetails.NetworkBandwidth,
+			"Burst":               experimentsDetails.Burst,
+			"Limit":               experimentsDetails.Limit,
+			"RateLimitDiscipline": experimentsDetails.RateLimitDiscipline,
+			"Sequence":            experimentsDetails.Sequence,
+			"PodsAffectedPerc":    experimentsDetails.PodsAffectedPerc,
+		})
+	case "network-reorder":
+		log.InfoWithValues("[Info]: The chaos tunables are:", logrus.Fields{
+			"NetworkPacketReorderPercentage": experimentsDetails.NetworkPacketReorderPercentage,
+			"ReorderCorrelation":             experimentsDetails.ReorderCorrelation,
+			"ReorderGap":                     experimentsDetails.ReorderGap,
+			"NetworkLatency":                 strconv.Itoa(experimentsDetails.NetworkLatency),
+			"Sequence":                       experimentsDetails.Sequence,
+			"PodsAffectedPerc":               experimentsDetails.PodsAffectedPerc,
+		})
+	case "network-degradation":
+		log.InfoWithValues("[Info]: The chaos tunables are:", logrus.Fields{
+			"NetworkLatency":                     strconv.Itoa(experimentsDetails.NetworkLatency),
+			"Jitter":                             experimentsDetails.Jitter,
+			"JitterDistribution":                 experimentsDetails.JitterDistribution,
+			"NetworkPacketLossPercentage":        experimentsDetails.NetworkPacketLossPercentage,
+			"LossCorrelation":                    experimentsDetails.LossCorrelation,
+			"NetworkPacketDuplicationPercentage": experimentsDetails.NetworkPacketDuplicationPercentage,
+			"NetworkPacketCorruptionPercentage":  experimentsDetails.NetworkPacketCorruptionPercentage,
+			"NetworkPacketReorderPercentage":     experimentsDetails.NetworkPacketReorderPercentage,
+			"ReorderCorrelation":                 experimentsDetails.ReorderCorrelation,
+			"ReorderGap":                         experimentsDetails.ReorderGap,
+			"Sequence":                           experimentsDetails.Sequence,
+			"PodsAffectedPerc":                   experimentsDetails.PodsAffectedPerc,
+		})
 	}
 }
@@ -0,0 +1,235 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/events"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/network-chaos/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/probe"
+	"github.com/litmuschaos/litmus-go/pkg/status"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/common"
+	"github.com/litmuschaos/litmus-go/pkg/utils/stringutils"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PrepareAndInjectNodeChaos contains the preparation & injection steps of the node level network chaos
+// the chaos is injected on the network interface of the node, inside the host network namespace
+// the allowlisted ips and the kube-apiserver are always excluded, so that the node doesn't lose the control plane
+func PrepareAndInjectNodeChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails, args string) error {
+
+	var err error
+	if err = validateFilters(experimentsDetails); err != nil {
+		return stacktrace.Propagate(err, "could not validate the traffic filters")
+	}
+	if err = validateSchedule(experimentsDetails); err != nil {
+		return stacktrace.Propagate(err, "could not validate the chaos schedule")
+	}
+
+	allowlist, err := getNodeAllowlist(experimentsDetails, clients)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not get the allowlist")
+	}
+	// the allowlisted ips are excluded from the chaos, along with the excluded ips
+	experimentsDetails.ExcludedIPs = strings.Join(uniqueIPs(append(allowlist, strings.Split(experimentsDetails.ExcludedIPs, ",")...)), ",")
+
+	//set up the tunables if provided in range
+	SetChaosTunables(experimentsDetails)
+	experimentsDetails.NodesAffectedPerc = common.ValidateRange(experimentsDetails.NodesAffectedPerc)
+	experimentsDetails.TargetType = "node"
+
+	log.InfoWithValues("[Info]: The chaos tunables are:", logrus.Fields{
+		"NetemCommand":             args,
+		"NetworkInterface":         experimentsDetails.NetworkInterface,
+		"Allowlist":                experimentsDetails.ExcludedIPs,
+		"Node Affected Percentage": experimentsDetails.NodesAffectedPerc,
+		"Sequence":                 experimentsDetails.Sequence,
+	})
+
+	//Select the nodes for the network chaos
+	nodesAffectedPerc, _ := strconv.Atoi(experimentsDetails.NodesAffectedPerc)
+	targetNodeList, err := common.GetNodeList(experimentsDetails.TargetNodes, experimentsDetails.NodeLabel, nodesAffectedPerc, clients)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not get node list")
+	}
+
+	log.InfoWithValues("[Info]: Details of Nodes under chaos injection", logrus.Fields{
+		"No. Of Nodes": len(targetNodeList),
+		"Node Names":   targetNodeList,
+	})
+
+	// the destination hosts are resolved inside the cluster, service mesh is not applicable for the nodes
+	if destIps == "" {
+		destIps, err = GetTargetIps(experimentsDetails.DestinationIPs, experimentsDetails.DestinationHosts, clients, false)
+		if err != nil {
+			return stacktrace.Propagate(err, "could not get destination ips")
+		}
+	}
+
+	//Waiting for the ramp time before chaos injection
+	if experimentsDetails.RampTime != 0 {
+		log.Infof("[Ramp]: Waiting for the %vs ramp time before injecting chaos", experimentsDetails.RampTime)
+		common.WaitForDuration(experimentsDetails.RampTime)
+	}
+
+	// Getting the serviceAccountName, need permission inside helper pod to create the events
+	if experimentsDetails.ChaosServiceAccount == "" {
+		experimentsDetails.ChaosServiceAccount, err = common.GetServiceAccount(experimentsDetails.ChaosNamespace, experimentsDetails.ChaosPodName, clients)
+		if err != nil {
+			return stacktrace.Propagate(err, "could not get experiment service account")
+		}
+	}
+
+	if experimentsDetails.EngineName != "" {
+		if err := common.SetHelperData(chaosDetails, experimentsDetails.SetHelperData, clients); err != nil {
+			return stacktrace.Propagate(err, "could not set helper data")
+		}
+	}
+
+	// run the probes during chaos
+	if len(resultDetails.ProbeDetails) != 0 {
+		if err := probe.RunProbes(chaosDetails, clients, resultDetails, "DuringChaos", eventsDetails); err != nil {
+			return err
+		}
+	}
+
+	switch strings.ToLower(experimentsDetails.Sequence) {
+	case "serial":
+		for _, node := range targetNodeList {
+			if err = injectNodeChaos(experimentsDetails, []string{node}, clients, eventsDetails, chaosDetails, args); err != nil {
+				return stacktrace.Propagate(err, "could not run chaos in serial mode")
+			}
+		}
+	case "parallel":
+		if err = injectNodeChaos(experimentsDetails, targetNodeList, clients, eventsDetails, chaosDetails, args); err != nil {
+			return stacktrace.Propagate(err, "could not run chaos in parallel mode")
+		}
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' sequence is not supported", experimentsDetails.Sequence)}
+	}
+
+	//Waiting for the ramp time after chaos injection
+	if experimentsDetails.RampTime != 0 {
+		log.Infof("[Ramp]: Waiting for the %vs ramp time after injecting chaos", experimentsDetails.RampTime)
+		common.WaitForDuration(experimentsDetails.RampTime)
+	}
+	return nil
+}
+
+// injectNodeChaos creates the helper pods for the given nodes at once and waits till the completion of the helper pods
+func injectNodeChaos(experimentsDetails *experimentTypes.ExperimentDetails, targetNodeList []string, clients clients.ClientSets, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails, args string) error {
+	runID := stringutils.GetRunID()
+
+	for _, appNode := range targetNodeList {
+		if experimentsDetails.EngineName != "" {
+			msg := "Injecting " + experimentsDetails.ExperimentName + " chaos on " + appNode + " node"
+			types.SetEngineEventAttributes(eventsDetails, types.ChaosInject, msg, "Normal", chaosDetails)
+			events.GenerateEvents(eventsDetails, clients, chaosDetails, "ChaosEngine")
+		}
+
+		// the node itself is the target of the helper pod
+		if err := createHelperPod(experimentsDetails, clients, chaosDetails, appNode, appNode, runID, args); err != nil {
+			return stacktrace.Propagate(err, "could not create helper pod")
+		}
+	}
+
+	appLabel := fmt.Sprintf("app=%s-helper-%s", experimentsDetails.ExperimentName, runID)
+
+	//checking the status of the helper pods, wait till the pod comes to running state else fail the experiment
+	log.Info("[Status]: Checking the status of the helper pods")
+	if err := status.CheckHelperStatus(experimentsDetails.ChaosNamespace, appLabel, experimentsDetails.Timeout, experimentsDetails.Delay, clients); err != nil {
+		common.DeleteAllHelperPodBasedOnJobCleanupPolicy(appLabel, chaosDetails, clients)
+		return stacktrace.Propagate(err, "could not check helper status")
+	}
+
+	for _, appNode := range targetNodeList {
+		common.SetTargets(appNode, "targeted", "node", chaosDetails)
+	}
+
+	// Wait till the completion of the helper pod
+	// set an upper limit for the waiting time
+	log.Info("[Wait]: waiting till the completion of the helper pod")
+	podStatus, err := status.WaitForCompletion(experimentsDetails.ChaosNamespace, appLabel, clients, experimentsDetails.ChaosDuration+experimentsDetails.Timeout, common.GetContainerNames(chaosDetails)...)
+	if err != nil || podStatus == "Failed" {
+		common.DeleteAllHelperPodBasedOnJobCleanupPolicy(appLabel, chaosDetails, clients)
+		return common.HelperFailedError(err, appLabel, chaosDetails.ChaosNamespace, false)
+	}
+
+	//Deleting all the helper pod for node network chaos
+	log.Info("[Cleanup]: Deleting all the helper pod")
+	if err := common.DeleteAllPod(appLabel, experimentsDetails.ChaosNamespace, chaosDetails.Timeout, chaosDetails.Delay, clients); err != nil {
+		return stacktrace.Propagate(err, "could not delete helper pod(s)")
+	}
+	return nil
+}
+
+// getNodeAllowlist returns the ips, which are excluded from the node level network chaos
+// it contains the user provided allowlist along with the cluster ip and the endpoints of the kube-apiserver
+// so that the kubelet to apiserver traffic is not affected, the allowlist must not be empty
+func getNodeAllowlist(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets) ([]string, error) {
+	var allowlist []string
+	for _, ip := range strings.Split(experimentsDetails.AllowlistIPs, ",") {
+		ip = strings.TrimSpace(ip)
+		if ip == "" {
+			continue
+		}
+		if !isValidIPOrCIDR(ip) {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' allowlist entry is neither a valid ip nor a valid cidr", ip)}
+		}
+		allowlist = append(allowlist, ip)
+	}
+
+	apiserverIPs, err := getAPIServerIPs(clients)
+	if err != nil {
+		if len(allowlist) == 0 {
+			return nil, stacktrace.Propagate(err, "could not derive the allowlist, provide the ALLOWLIST_IPS")
+		}
+		log.Warnf("[Info]: Unable to derive the kube-apiserver ips, only the ALLOWLIST_IPS are excluded, err: %v", err)
+	}
+	allowlist = append(allowlist, apiserverIPs...)
+
+	if len(allowlist) == 0 {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "allowlist is empty, provide the ALLOWLIST_IPS"}
+	}
+	return uniqueIPs(allowlist), nil
+}
+
+// getAPIServerIPs returns the cluster ip and the endpoint ips of the kubernetes service
+func getAPIServerIPs(clients clients.ClientSets) ([]string, error) {
+	svc, err := clients.KubeClient.CoreV1().Services("default").Get(context.Background(), "kubernetes", v1.GetOptions{})
+	if err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: "{serviceName: kubernetes, namespace: default}", Reason: fmt.Sprintf("failed to get the service: %s", err.Error())}
+	}
+	endpoints, err := clients.KubeClient.CoreV1().Endpoints("default").Get(context.Background(), "kubernetes", v1.GetOptions{})
+	if err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: "{serviceName: kubernetes, namespace: default}", Reason: fmt.Sprintf("failed to get the endpoints: %s", err.Error())}
+	}
+
+	ips := append([]string{}, svc.Spec.ClusterIPs...)
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			ips = append(ips, address.IP)
+		}
+	}
+	return ips, nil
+}
+
+// uniqueIPs removes the duplicate and empty ips from the list
+func uniqueIPs(ips []string) []string {
+	var unique []string
+	for _, ip := range ips {
+		ip = strings.TrimSpace(ip)
+		if ip != "" && !common.Contains(ip, unique) {
+			unique = append(unique, ip)
+		}
+	}
+	return unique
+}
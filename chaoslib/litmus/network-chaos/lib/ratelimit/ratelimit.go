@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+
+	network_chaos "github.com/litmuschaos/litmus-go/chaoslib/litmus/network-chaos/lib"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/network-chaos/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+)
+
+// PodNetworkRateLimitChaos contains the steps to prepare and inject chaos
+func PodNetworkRateLimitChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails) error {
+
+	args, err := getRateLimitArgs(experimentsDetails)
+	if err != nil {
+		return err
+	}
+	return network_chaos.PrepareAndInjectChaos(experimentsDetails, clients, resultDetails, eventsDetails, chaosDetails, args)
+}
+
+// getRateLimitArgs derives the qdisc and its arguments based on the rate limit discipline
+// tbf limits the throughput using token bucket, where the limit is the size of the queue in bytes
+// netem limits the throughput using rate, where the limit is the size of the queue in packets
+func getRateLimitArgs(experimentsDetails *experimentTypes.ExperimentDetails) (string, error) {
+	if strings.TrimSpace(experimentsDetails.NetworkBandwidth) == "" {
+		return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "provide the NETWORK_BANDWIDTH to limit the throughput"}
+	}
+
+	switch strings.ToLower(experimentsDetails.RateLimitDiscipline) {
+	case "tbf":
+		// tbf requires both the burst and the limit, defaults are used if they are not provided
+		if experimentsDetails.Burst == "" {
+			experimentsDetails.Burst = "32kb"
+		}
+		if experimentsDetails.Limit == "" {
+			experimentsDetails.Limit = "2mb"
+		}
+		experimentsDetails.Qdisc = "tbf"
+		return fmt.Sprintf("rate %v burst %v limit %v", experimentsDetails.NetworkBandwidth, experimentsDetails.Burst, experimentsDetails.Limit), nil
+	case "netem":
+		if experimentsDetails.Burst != "" {
+			log.Warn("[Info]: BURST is not applicable for the netem rate limit discipline, it will be ignored")
+		}
+		experimentsDetails.Qdisc = "netem"
+		args := fmt.Sprintf("rate %v", experimentsDetails.NetworkBandwidth)
+		if experimentsDetails.Limit != "" {
+			args += fmt.Sprintf(" limit %v", experimentsDetails.Limit)
+		}
+		return args, nil
+	default:
+		return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' rate limit discipline is not supported, it should be one of tbf or netem", experimentsDetails.RateLimitDiscipline)}
+	}
+}
@@ -0,0 +1,27 @@
+package reorder
+
+import (
+	"fmt"
+
+	network_chaos "github.com/litmuschaos/litmus-go/chaoslib/litmus/network-chaos/lib"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/network-chaos/types"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+)
+
+// PodNetworkReorderChaos contains the steps to prepare and inject chaos
+func PodNetworkReorderChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails) error {
+
+	// netem reorders the packets by sending them immediately, while rest of the packets are delayed
+	// so the reordering takes effect only if the latency is provided
+	if experimentsDetails.NetworkLatency <= 0 {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: "provide the NETWORK_LATENCY greater than 0, packets are reordered only if they are delayed"}
+	}
+
+	args := fmt.Sprintf("delay %vms reorder %v %v", experimentsDetails.NetworkLatency, experimentsDetails.NetworkPacketReorderPercentage, experimentsDetails.ReorderCorrelation)
+	if experimentsDetails.ReorderGap > 0 {
+		args += fmt.Sprintf(" gap %v", experimentsDetails.ReorderGap)
+	}
+	return network_chaos.PrepareAndInjectChaos(experimentsDetails, clients, resultDetails, eventsDetails, chaosDetails, args)
+}
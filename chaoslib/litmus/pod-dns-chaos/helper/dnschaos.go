@@ -13,6 +13,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/litmuschaos/litmus-go/chaoslib/litmus/pod-dns-chaos/interceptor"
 	clients "github.com/litmuschaos/litmus-go/pkg/clients"
 	"github.com/litmuschaos/litmus-go/pkg/events"
 	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/pod-dns-chaos/types"
@@ -203,18 +204,24 @@ func preparePodDNSChaos(experimentsDetails *experimentTypes.ExperimentDetails, c
 	return nil
 }
 
+// injectChaos starts the dns interceptor inside the network namespace of the target container
+// the interceptor is served by the same helper binary, its logs are streamed to the helper logs
 func injectChaos(experimentsDetails *experimentTypes.ExperimentDetails, t targetDetails) (*exec.Cmd, error) {
 
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Source: experimentsDetails.ChaosPodName, Target: fmt.Sprintf("{podName: %s, namespace: %s}", t.Name, t.Namespace), Reason: fmt.Sprintf("failed to get the helper executable: %s", err.Error())}
+	}
+
 	// prepare dns interceptor
-	var out bytes.Buffer
-	commandTemplate := fmt.Sprintf("sudo TARGET_PID=%d CHAOS_TYPE=%s SPOOF_MAP='%s' TARGET_HOSTNAMES='%s' CHAOS_DURATION=%d MATCH_SCHEME=%s nsutil -p -n -t %d -- dns_interceptor", t.Pid, experimentsDetails.ChaosType, experimentsDetails.SpoofMap, experimentsDetails.TargetHostNames, experimentsDetails.ChaosDuration, experimentsDetails.MatchScheme, t.Pid)
+	commandTemplate := fmt.Sprintf("sudo TARGET_PID=%d CHAOS_TYPE=%s SPOOF_MAP='%s' TARGET_HOSTNAMES='%s' CHAOS_DURATION=%d MATCH_SCHEME=%s RECORD_TYPES='%s' FAILURE_PERCENTAGE=%d DNS_LATENCY=%d nsenter -t %d -n %s -name %s", t.Pid, experimentsDetails.ChaosType, experimentsDetails.SpoofMap, experimentsDetails.TargetHostNames, experimentsDetails.ChaosDuration, experimentsDetails.MatchScheme, experimentsDetails.RecordTypes, experimentsDetails.FailurePercentage, experimentsDetails.DNSLatency, t.Pid, executable, interceptor.Name)
 	cmd := exec.Command("/bin/bash", "-c", commandTemplate)
 	log.Info(cmd.String())
-	cmd.Stdout = &out
-	cmd.Stderr = &out
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
 
 	if err = cmd.Start(); err != nil {
-		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Source: experimentsDetails.ChaosPodName, Target: fmt.Sprintf("{podName: %s, namespace: %s}", t.Name, t.Namespace), Reason: fmt.Sprintf("faild to inject chaos: %s", out.String())}
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Source: experimentsDetails.ChaosPodName, Target: fmt.Sprintf("{podName: %s, namespace: %s}", t.Name, t.Namespace), Reason: fmt.Sprintf("faild to inject chaos: %s", err.Error())}
 	}
 	return cmd, nil
 }
@@ -278,6 +285,9 @@ func getENV(experimentDetails *experimentTypes.ExperimentDetails) {
 	experimentDetails.SpoofMap = types.Getenv("SPOOF_MAP", "")
 	experimentDetails.MatchScheme = types.Getenv("MATCH_SCHEME", "exact")
 	experimentDetails.ChaosType = types.Getenv("CHAOS_TYPE", "error")
+	experimentDetails.RecordTypes = types.Getenv("RECORD_TYPES", "")
+	experimentDetails.FailurePercentage, _ = strconv.Atoi(types.Getenv("FAILURE_PERCENTAGE", "100"))
+	experimentDetails.DNSLatency, _ = strconv.Atoi(types.Getenv("DNS_LATENCY", "0"))
 	experimentDetails.SocketPath = types.Getenv("SOCKET_PATH", "")
 }
 
@@ -0,0 +1,269 @@
+package interceptor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/pod-dns-chaos/types"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// the supported chaos types
+const (
+	// ActionForward forwards the query to the upstream nameserver, without any fault
+	ActionForward = "forward"
+	// ActionError responds with NXDOMAIN
+	ActionError = "error"
+	// ActionSpoof resolves the query with the spoofed hostname
+	ActionSpoof = "spoof"
+	// ActionServFail responds with SERVFAIL
+	ActionServFail = "servfail"
+	// ActionRefused responds with REFUSED
+	ActionRefused = "refused"
+	// ActionTruncate responds with an empty truncated response, the clients should retry over tcp
+	ActionTruncate = "truncate"
+	// ActionLatency delays the query before forwarding it to the upstream nameserver
+	ActionLatency = "latency"
+)
+
+// the supported match schemes
+const (
+	matchExact     = "exact"
+	matchSubstring = "substring"
+	matchRegex     = "regex"
+)
+
+// recordTypes contains the supported record types
+var recordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"SRV":   dnsmessage.TypeSRV,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"PTR":   dnsmessage.TypePTR,
+	"TXT":   dnsmessage.TypeTXT,
+}
+
+// Config contains the validated fault details of the dns interceptor
+type Config struct {
+	ChaosType         string
+	MatchScheme       string
+	FailurePercentage int
+	Latency           time.Duration
+	RecordTypes       []dnsmessage.Type
+	targets           []matcher
+	spoofTargets      []matcher
+}
+
+// matcher matches the query names against a target hostname
+// spoof contains the spoofed hostname for the spoof chaos
+type matcher struct {
+	name    string
+	spoof   string
+	pattern *regexp.Regexp
+}
+
+// decision is the verdict of the interceptor for a query
+type decision struct {
+	Action string
+	Spoof  string
+	Reason string
+}
+
+// NewConfig validates the dns chaos tunables and derives the interceptor config
+func NewConfig(experimentsDetails *experimentTypes.ExperimentDetails) (*Config, error) {
+	config := &Config{
+		ChaosType:         strings.ToLower(experimentsDetails.ChaosType),
+		MatchScheme:       strings.ToLower(experimentsDetails.MatchScheme),
+		FailurePercentage: experimentsDetails.FailurePercentage,
+		Latency:           time.Duration(experimentsDetails.DNSLatency) * time.Millisecond,
+	}
+	if config.MatchScheme == "" {
+		config.MatchScheme = matchExact
+	}
+
+	switch config.ChaosType {
+	case ActionError, ActionSpoof, ActionServFail, ActionRefused, ActionTruncate:
+	case ActionLatency:
+		if config.Latency <= 0 {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%d' dns latency is invalid, it should be greater than 0ms", experimentsDetails.DNSLatency)}
+		}
+	default:
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' chaos type is not supported, it should be one of error, spoof, servfail, refused, truncate or latency", experimentsDetails.ChaosType)}
+	}
+
+	switch config.MatchScheme {
+	case matchExact, matchSubstring, matchRegex:
+	default:
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' match scheme is not supported, it should be one of exact, substring or regex", experimentsDetails.MatchScheme)}
+	}
+
+	if config.FailurePercentage < 0 || config.FailurePercentage > 100 {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%d' failure percentage is invalid, it should be in range [0,100]", config.FailurePercentage)}
+	}
+
+	for _, recordType := range strings.Split(experimentsDetails.RecordTypes, ",") {
+		recordType = strings.ToUpper(strings.TrimSpace(recordType))
+		if recordType == "" {
+			continue
+		}
+		t, ok := recordTypes[recordType]
+		if !ok {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' record type is not supported", recordType)}
+		}
+		config.RecordTypes = append(config.RecordTypes, t)
+	}
+
+	var err error
+	if config.ChaosType == ActionSpoof {
+		config.spoofTargets, err = getSpoofTargets(experimentsDetails.SpoofMap, config.MatchScheme)
+	} else {
+		config.targets, err = getTargets(experimentsDetails.TargetHostNames, config.MatchScheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// getTargets parses the target hostnames, provided in the json list format
+// all the queries are targeted, if the target hostnames are not provided
+func getTargets(targetHostNames, matchScheme string) ([]matcher, error) {
+	if strings.TrimSpace(targetHostNames) == "" {
+		return nil, nil
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(targetHostNames), &names); err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("failed to parse the target hostnames, it should be a json list: %s", err.Error())}
+	}
+	var targets []matcher
+	for _, name := range names {
+		m, err := newMatcher(name, matchScheme)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, m)
+	}
+	return targets, nil
+}
+
+// getSpoofTargets parses the spoof map, provided in the json map format
+// none of the queries are spoofed, if the spoof map is not provided
+func getSpoofTargets(spoofMap, matchScheme string) ([]matcher, error) {
+	if strings.TrimSpace(spoofMap) == "" {
+		return nil, nil
+	}
+	mapping := map[string]string{}
+	if err := json.Unmarshal([]byte(spoofMap), &mapping); err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("failed to parse the spoof map, it should be a json map: %s", err.Error())}
+	}
+
+	// the names are sorted, so that the overlapping entries are matched in the same order every time
+	var names []string
+	for name := range mapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var targets []matcher
+	for _, name := range names {
+		if _, err := dnsmessage.NewName(fqdn(mapping[name])); err != nil || normalize(mapping[name]) == "" {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' spoofed hostname is invalid", mapping[name])}
+		}
+		m, err := newMatcher(name, matchScheme)
+		if err != nil {
+			return nil, err
+		}
+		m.spoof = normalize(mapping[name])
+		targets = append(targets, m)
+	}
+	return targets, nil
+}
+
+// newMatcher creates the matcher for the hostname, the regex is compiled for the regex match scheme
+func newMatcher(name, matchScheme string) (matcher, error) {
+	if matchScheme == matchRegex {
+		pattern, err := regexp.Compile(name)
+		if err != nil {
+			return matcher{}, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' hostname is not a valid regex: %s", name, err.Error())}
+		}
+		return matcher{name: name, pattern: pattern}, nil
+	}
+	return matcher{name: normalize(name)}, nil
+}
+
+// match checks whether the query name matches the target hostname
+func (m matcher) match(name, matchScheme string) bool {
+	switch matchScheme {
+	case matchSubstring:
+		return strings.Contains(name, m.name)
+	case matchRegex:
+		return m.pattern.MatchString(name)
+	default:
+		return name == m.name
+	}
+}
+
+// decide returns the decision for the query, based on the name and the record type of the query
+func (c *Config) decide(name string, recordType dnsmessage.Type) decision {
+	name = normalize(name)
+
+	if len(c.RecordTypes) != 0 && !containsType(c.RecordTypes, recordType) {
+		return decision{Action: ActionForward, Reason: "record type not targeted"}
+	}
+
+	var spoof string
+	if c.ChaosType == ActionSpoof {
+		m, ok := findMatch(c.spoofTargets, name, c.MatchScheme)
+		if !ok {
+			return decision{Action: ActionForward, Reason: "hostname not targeted"}
+		}
+		spoof = m.spoof
+	} else if len(c.targets) != 0 {
+		if _, ok := findMatch(c.targets, name, c.MatchScheme); !ok {
+			return decision{Action: ActionForward, Reason: "hostname not targeted"}
+		}
+	}
+
+	if c.FailurePercentage < 100 && rand.Intn(100) >= c.FailurePercentage {
+		return decision{Action: ActionForward, Reason: "skipped by failure percentage"}
+	}
+	return decision{Action: c.ChaosType, Spoof: spoof, Reason: "hostname targeted"}
+}
+
+// findMatch returns the first matcher, which matches the query name
+func findMatch(matchers []matcher, name, matchScheme string) (matcher, bool) {
+	for _, m := range matchers {
+		if m.match(name, matchScheme) {
+			return m, true
+		}
+	}
+	return matcher{}, false
+}
+
+// containsType checks whether the record type is present inside the list
+func containsType(types []dnsmessage.Type, recordType dnsmessage.Type) bool {
+	for _, t := range types {
+		if t == recordType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize converts the hostname into lowercase, without the trailing dot
+func normalize(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// fqdn returns the fully qualified form of the hostname
+func fqdn(name string) string {
+	return normalize(name) + "."
+}
@@ -0,0 +1,191 @@
+package interceptor
+
+import (
+	"testing"
+	"time"
+
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/pod-dns-chaos/types"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestNewConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		details experimentTypes.ExperimentDetails
+		wantErr bool
+	}{
+		{
+			name:    "error chaos with the default match scheme",
+			details: experimentTypes.ExperimentDetails{ChaosType: "error", TargetHostNames: `["google.com"]`, FailurePercentage: 100},
+		},
+		{
+			name:    "chaos type is case insensitive",
+			details: experimentTypes.ExperimentDetails{ChaosType: "SERVFAIL", MatchScheme: "Exact", FailurePercentage: 100},
+		},
+		{
+			name:    "spoof chaos with the spoof map",
+			details: experimentTypes.ExperimentDetails{ChaosType: "spoof", SpoofMap: `{"google.com":"fake.com"}`, FailurePercentage: 100},
+		},
+		{
+			name:    "latency chaos with the latency",
+			details: experimentTypes.ExperimentDetails{ChaosType: "latency", DNSLatency: 200, FailurePercentage: 100},
+		},
+		{
+			name:    "latency chaos without the latency",
+			details: experimentTypes.ExperimentDetails{ChaosType: "latency", FailurePercentage: 100},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported chaos type",
+			details: experimentTypes.ExperimentDetails{ChaosType: "drop", FailurePercentage: 100},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported match scheme",
+			details: experimentTypes.ExperimentDetails{ChaosType: "error", MatchScheme: "prefix", FailurePercentage: 100},
+			wantErr: true,
+		},
+		{
+			name:    "failure percentage out of range",
+			details: experimentTypes.ExperimentDetails{ChaosType: "error", FailurePercentage: 101},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported record type",
+			details: experimentTypes.ExperimentDetails{ChaosType: "error", RecordTypes: "A,SOA", FailurePercentage: 100},
+			wantErr: true,
+		},
+		{
+			name:    "target hostnames not in the json list format",
+			details: experimentTypes.ExperimentDetails{ChaosType: "error", TargetHostNames: "google.com", FailurePercentage: 100},
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			details: experimentTypes.ExperimentDetails{ChaosType: "error", MatchScheme: "regex", TargetHostNames: `["("]`, FailurePercentage: 100},
+			wantErr: true,
+		},
+		{
+			name:    "invalid spoofed hostname",
+			details: experimentTypes.ExperimentDetails{ChaosType: "spoof", SpoofMap: `{"google.com":""}`, FailurePercentage: 100},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewConfig(&tt.details)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && config.MatchScheme == "" {
+				t.Errorf("NewConfig() match scheme is not defaulted")
+			}
+		})
+	}
+}
+
+func TestNewConfigDerivesTunables(t *testing.T) {
+	config, err := NewConfig(&experimentTypes.ExperimentDetails{ChaosType: "Latency", DNSLatency: 150, RecordTypes: " a, aaaa ,", FailurePercentage: 50})
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	if config.ChaosType != ActionLatency {
+		t.Errorf("ChaosType = %v, want %v", config.ChaosType, ActionLatency)
+	}
+	if config.MatchScheme != matchExact {
+		t.Errorf("MatchScheme = %v, want %v", config.MatchScheme, matchExact)
+	}
+	if config.Latency != 150*time.Millisecond {
+		t.Errorf("Latency = %v, want %v", config.Latency, 150*time.Millisecond)
+	}
+	if len(config.RecordTypes) != 2 || config.RecordTypes[0] != dnsmessage.TypeA || config.RecordTypes[1] != dnsmessage.TypeAAAA {
+		t.Errorf("RecordTypes = %v, want [A AAAA]", config.RecordTypes)
+	}
+}
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name       string
+		details    experimentTypes.ExperimentDetails
+		query      string
+		recordType dnsmessage.Type
+		want       decision
+	}{
+		{
+			name:       "all the queries are targeted without the target hostnames",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "error", FailurePercentage: 100},
+			query:      "example.com.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionError, Reason: "hostname targeted"},
+		},
+		{
+			name:       "exact match is case insensitive and ignores the trailing dot",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "refused", TargetHostNames: `["Google.com"]`, FailurePercentage: 100},
+			query:      "google.COM.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionRefused, Reason: "hostname targeted"},
+		},
+		{
+			name:       "exact match doesn't match the subdomains",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "error", TargetHostNames: `["google.com"]`, FailurePercentage: 100},
+			query:      "mail.google.com.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionForward, Reason: "hostname not targeted"},
+		},
+		{
+			name:       "substring match",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "servfail", MatchScheme: "substring", TargetHostNames: `["google"]`, FailurePercentage: 100},
+			query:      "mail.google.com.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionServFail, Reason: "hostname targeted"},
+		},
+		{
+			name:       "regex match",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "truncate", MatchScheme: "regex", TargetHostNames: `["^.*\\.svc\\.cluster\\.local$"]`, FailurePercentage: 100},
+			query:      "nginx.default.svc.cluster.local.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionTruncate, Reason: "hostname targeted"},
+		},
+		{
+			name:       "record type not targeted",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "error", RecordTypes: "AAAA", FailurePercentage: 100},
+			query:      "google.com.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionForward, Reason: "record type not targeted"},
+		},
+		{
+			name:       "spoofed hostname of the matching entry",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "spoof", SpoofMap: `{"google.com":"Fake.com."}`, FailurePercentage: 100},
+			query:      "google.com.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionSpoof, Spoof: "fake.com", Reason: "hostname targeted"},
+		},
+		{
+			name:       "spoof chaos forwards the hostnames outside the spoof map",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "spoof", SpoofMap: `{"google.com":"fake.com"}`, FailurePercentage: 100},
+			query:      "example.com.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionForward, Reason: "hostname not targeted"},
+		},
+		{
+			name:       "zero failure percentage forwards all the queries",
+			details:    experimentTypes.ExperimentDetails{ChaosType: "error", FailurePercentage: 0},
+			query:      "google.com.",
+			recordType: dnsmessage.TypeA,
+			want:       decision{Action: ActionForward, Reason: "skipped by failure percentage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewConfig(&tt.details)
+			if err != nil {
+				t.Fatalf("NewConfig() error = %v", err)
+			}
+			if got := config.decide(tt.query, tt.recordType); got != tt.want {
+				t.Errorf("decide() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package interceptor
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/pod-dns-chaos/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+)
+
+// Name is the helper name of the dns interceptor
+const Name = "dns-interceptor"
+
+// Interceptor intercepts the dns queries of the target container and injects the faults on them
+// it runs inside the network namespace of the target container till the chaos duration or a termination signal
+func Interceptor() {
+
+	experimentsDetails := experimentTypes.ExperimentDetails{}
+	getENV(&experimentsDetails)
+
+	if err := intercept(&experimentsDetails); err != nil {
+		log.Fatalf("dns interceptor failed, err: %v", err)
+	}
+}
+
+// intercept redirects the dns queries to the interceptor and reverts the redirection at the end
+func intercept(experimentsDetails *experimentTypes.ExperimentDetails) error {
+
+	rand.Seed(time.Now().UnixNano())
+
+	config, err := NewConfig(experimentsDetails)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not validate the dns chaos tunables")
+	}
+
+	pid, _ := strconv.Atoi(types.Getenv("TARGET_PID", "0"))
+	upstreams, err := getUpstreams(pid)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not get the upstream nameservers")
+	}
+
+	srv := newServer(config, upstreams)
+	if err := srv.listen(); err != nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Reason: fmt.Sprintf("failed to listen for the dns queries: %s", err.Error())}
+	}
+	defer srv.close()
+	go srv.serve()
+
+	// the signals are registered before adding the rules, so that the rules are removed if the interceptor is terminated in between
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	// stale rules of an earlier interceptor are removed, before adding the new ones
+	if err := removeRedirectRules(getBinaries(false)); err != nil {
+		return stacktrace.Propagate(err, "could not remove the stale redirect rules")
+	}
+	if err := addRedirectRules("iptables", srv.port); err != nil {
+		if revertErr := removeRedirectRules(getBinaries(false)); revertErr != nil {
+			return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s,%s]", stacktrace.RootCause(err).Error(), stacktrace.RootCause(revertErr).Error())}
+		}
+		return stacktrace.Propagate(err, "could not add the redirect rules")
+	}
+	// ipv6 interception is best effort, the nat table may not be available for ipv6
+	if srv.ipv6 {
+		if err := redirectIPv6(srv.port); err != nil {
+			log.Warnf("[Info]: Unable to redirect the ipv6 dns queries, only the ipv4 queries are intercepted, err: %v", err)
+			if revertErr := removeRedirectRules([]string{"ip6tables"}); revertErr != nil {
+				log.Errorf("unable to remove the ip6tables redirect rules, err: %v", revertErr)
+			}
+			srv.ipv6 = false
+		}
+	}
+
+	log.InfoWithValues("[Interceptor]: The dns interceptor is started with the following tunables", logrus.Fields{
+		"ChaosType":         config.ChaosType,
+		"MatchScheme":       config.MatchScheme,
+		"FailurePercentage": config.FailurePercentage,
+		"Latency":           config.Latency.String(),
+		"RecordTypes":       experimentsDetails.RecordTypes,
+		"Upstreams":         upstreams,
+	})
+
+	select {
+	case <-time.After(time.Duration(experimentsDetails.ChaosDuration) * time.Second):
+		log.Info("[Interceptor]: Chaos duration is over")
+	case sig := <-signals:
+		log.Infof("[Interceptor]: %v signal received", sig)
+	}
+
+	err = removeRedirectRules(getBinaries(srv.ipv6))
+	log.InfoWithValues("[Interceptor]: Summary of the intercepted queries", srv.counter.summary())
+	return err
+}
+
+// getUpstreams returns the nameservers of the target container from its resolv.conf
+func getUpstreams(pid int) ([]string, error) {
+	path := fmt.Sprintf("/proc/%d/root/etc/resolv.conf", pid)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Reason: fmt.Sprintf("failed to read the resolv.conf of the target: %s", err.Error())}
+	}
+	defer file.Close()
+
+	var upstreams []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			upstreams = append(upstreams, fields[1])
+		}
+	}
+	if len(upstreams) == 0 {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Reason: fmt.Sprintf("no nameserver found inside %s", path)}
+	}
+	return upstreams, nil
+}
+
+// getENV fetches all the env variables passed by the dns helper
+func getENV(experimentDetails *experimentTypes.ExperimentDetails) {
+	experimentDetails.ChaosDuration, _ = strconv.Atoi(types.Getenv("CHAOS_DURATION", "60"))
+	experimentDetails.TargetHostNames = types.Getenv("TARGET_HOSTNAMES", "")
+	experimentDetails.SpoofMap = types.Getenv("SPOOF_MAP", "")
+	experimentDetails.MatchScheme = types.Getenv("MATCH_SCHEME", "exact")
+	experimentDetails.ChaosType = types.Getenv("CHAOS_TYPE", "error")
+	experimentDetails.RecordTypes = types.Getenv("RECORD_TYPES", "")
+	experimentDetails.FailurePercentage, _ = strconv.Atoi(types.Getenv("FAILURE_PERCENTAGE", "100"))
+	experimentDetails.DNSLatency, _ = strconv.Atoi(types.Getenv("DNS_LATENCY", "0"))
+}
@@ -0,0 +1,17 @@
+package interceptor
+
+import (
+	"syscall"
+)
+
+// markSocket sets the interceptor mark on the upstream socket
+// the marked packets skip the redirect rules, otherwise the upstream queries would loop back to the interceptor
+func markSocket(network, address string, c syscall.RawConn) error {
+	var err error
+	if ctrlErr := c.Control(func(fd uintptr) {
+		err = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_MARK, upstreamMark)
+	}); ctrlErr != nil {
+		return ctrlErr
+	}
+	return err
+}
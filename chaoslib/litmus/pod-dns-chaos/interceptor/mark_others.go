@@ -0,0 +1,12 @@
+//go:build !linux
+
+package interceptor
+
+import (
+	"syscall"
+)
+
+// markSocket is a no-op, the redirect rules are only supported on linux
+func markSocket(network, address string, c syscall.RawConn) error {
+	return nil
+}
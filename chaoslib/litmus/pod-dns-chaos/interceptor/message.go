@@ -0,0 +1,93 @@
+package interceptor
+
+import (
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// query contains the parsed header and the questions of the intercepted query
+type query struct {
+	header    dnsmessage.Header
+	questions []dnsmessage.Question
+}
+
+// parseQuery parses the header and the questions of the raw query
+func parseQuery(msg []byte) (query, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(msg)
+	if err != nil {
+		return query{}, err
+	}
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return query{}, err
+	}
+	return query{header: header, questions: questions}, nil
+}
+
+// newResponse builds the response of the query with the given rcode
+// it contains only the question section, the truncated flag is set for the truncate chaos
+func newResponse(q query, rcode dnsmessage.RCode, truncated bool) ([]byte, error) {
+	b := dnsmessage.NewBuilder(make([]byte, 0, 512), dnsmessage.Header{
+		ID:                 q.header.ID,
+		Response:           true,
+		OpCode:             q.header.OpCode,
+		RecursionDesired:   q.header.RecursionDesired,
+		RecursionAvailable: true,
+		Truncated:          truncated,
+		RCode:              rcode,
+	})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	for _, question := range q.questions {
+		if err := b.Question(question); err != nil {
+			return nil, err
+		}
+	}
+	return b.Finish()
+}
+
+// spoofQuery replaces the name of the first question with the spoofed hostname
+// it returns the packed query along with the original and the spoofed names
+func spoofQuery(msg []byte, spoof string) ([]byte, dnsmessage.Name, dnsmessage.Name, error) {
+	var m dnsmessage.Message
+	if err := m.Unpack(msg); err != nil {
+		return nil, dnsmessage.Name{}, dnsmessage.Name{}, err
+	}
+	spoofed, err := dnsmessage.NewName(fqdn(spoof))
+	if err != nil {
+		return nil, dnsmessage.Name{}, dnsmessage.Name{}, err
+	}
+	original := m.Questions[0].Name
+	m.Questions[0].Name = spoofed
+	packed, err := m.Pack()
+	return packed, original, spoofed, err
+}
+
+// restoreResponse renames the records of the spoofed hostname back to the original name
+// so that the client accepts the response of the spoofed query for the original query
+func restoreResponse(msg []byte, original, spoofed dnsmessage.Name) ([]byte, error) {
+	var m dnsmessage.Message
+	if err := m.Unpack(msg); err != nil {
+		return nil, err
+	}
+	for i := range m.Questions {
+		if sameName(m.Questions[i].Name, spoofed) {
+			m.Questions[i].Name = original
+		}
+	}
+	for i := range m.Answers {
+		if sameName(m.Answers[i].Header.Name, spoofed) {
+			m.Answers[i].Header.Name = original
+		}
+	}
+	return m.Pack()
+}
+
+// sameName compares the names, case insensitively
+func sameName(a, b dnsmessage.Name) bool {
+	return strings.EqualFold(a.String(), b.String())
+}
@@ -0,0 +1,131 @@
+package interceptor
+
+import (
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// packQuery packs a recursive query of the given name and record type
+func packQuery(t *testing.T, name string, recordType dnsmessage.Type) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 0x1234, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: dnsmessage.MustNewName(name), Type: recordType, Class: dnsmessage.ClassINET},
+		},
+	}
+	packed, err := msg.Pack()
+	if err != nil {
+		t.Fatalf("unable to pack the query, err: %v", err)
+	}
+	return packed
+}
+
+func TestNewResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		rcode     dnsmessage.RCode
+		truncated bool
+	}{
+		{name: "nxdomain", rcode: dnsmessage.RCodeNameError},
+		{name: "servfail", rcode: dnsmessage.RCodeServerFailure},
+		{name: "refused", rcode: dnsmessage.RCodeRefused},
+		{name: "truncated", rcode: dnsmessage.RCodeSuccess, truncated: true},
+	}
+
+	q, err := parseQuery(packQuery(t, "google.com.", dnsmessage.TypeAAAA))
+	if err != nil {
+		t.Fatalf("parseQuery() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packed, err := newResponse(q, tt.rcode, tt.truncated)
+			if err != nil {
+				t.Fatalf("newResponse() error = %v", err)
+			}
+			var resp dnsmessage.Message
+			if err := resp.Unpack(packed); err != nil {
+				t.Fatalf("unable to unpack the response, err: %v", err)
+			}
+			if resp.Header.ID != 0x1234 || !resp.Header.Response || !resp.Header.RecursionDesired {
+				t.Errorf("header = %+v, want the id and the recursion desired flag of the query", resp.Header)
+			}
+			if resp.Header.RCode != tt.rcode || resp.Header.Truncated != tt.truncated {
+				t.Errorf("rcode = %v, truncated = %v, want %v, %v", resp.Header.RCode, resp.Header.Truncated, tt.rcode, tt.truncated)
+			}
+			if len(resp.Questions) != 1 || resp.Questions[0] != q.questions[0] {
+				t.Errorf("questions = %v, want %v", resp.Questions, q.questions)
+			}
+			if len(resp.Answers) != 0 {
+				t.Errorf("answers = %v, want none", resp.Answers)
+			}
+		})
+	}
+}
+
+func TestSpoofQueryAndRestoreResponse(t *testing.T) {
+	packed, original, spoofed, err := spoofQuery(packQuery(t, "Google.com.", dnsmessage.TypeA), "fake.com")
+	if err != nil {
+		t.Fatalf("spoofQuery() error = %v", err)
+	}
+	if original.String() != "Google.com." || spoofed.String() != "fake.com." {
+		t.Errorf("original = %v, spoofed = %v, want Google.com. and fake.com.", original, spoofed)
+	}
+
+	var query dnsmessage.Message
+	if err := query.Unpack(packed); err != nil {
+		t.Fatalf("unable to unpack the spoofed query, err: %v", err)
+	}
+	if query.Header.ID != 0x1234 || query.Questions[0].Name != spoofed || query.Questions[0].Type != dnsmessage.TypeA {
+		t.Fatalf("spoofed query = %+v, want the question of fake.com. with the original id", query)
+	}
+
+	// the upstream response of the spoofed query, the answers of the other names are left as it is
+	upstream := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 0x1234, Response: true, RecursionDesired: true, RecursionAvailable: true},
+		Questions: query.Questions,
+		Answers: []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("FAKE.com."), Type: dnsmessage.TypeCNAME, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("edge.fake.com.")},
+			},
+			{
+				Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("edge.fake.com."), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}},
+			},
+		},
+	}
+	upstreamPacked, err := upstream.Pack()
+	if err != nil {
+		t.Fatalf("unable to pack the upstream response, err: %v", err)
+	}
+
+	restored, err := restoreResponse(upstreamPacked, original, spoofed)
+	if err != nil {
+		t.Fatalf("restoreResponse() error = %v", err)
+	}
+	var resp dnsmessage.Message
+	if err := resp.Unpack(restored); err != nil {
+		t.Fatalf("unable to unpack the restored response, err: %v", err)
+	}
+	if resp.Questions[0].Name != original {
+		t.Errorf("question = %v, want %v", resp.Questions[0].Name, original)
+	}
+	if resp.Answers[0].Header.Name != original {
+		t.Errorf("first answer = %v, want %v", resp.Answers[0].Header.Name, original)
+	}
+	if resp.Answers[1].Header.Name.String() != "edge.fake.com." {
+		t.Errorf("second answer = %v, want edge.fake.com.", resp.Answers[1].Header.Name)
+	}
+	if a, ok := resp.Answers[1].Body.(*dnsmessage.AResource); !ok || a.A != [4]byte{10, 0, 0, 1} {
+		t.Errorf("second answer body = %v, want 10.0.0.1", resp.Answers[1].Body)
+	}
+}
+
+func TestSpoofQueryInvalidMessage(t *testing.T) {
+	if _, _, _, err := spoofQuery([]byte{0x12}, "fake.com"); err == nil {
+		t.Errorf("spoofQuery() error = nil, want error for the malformed query")
+	}
+}
@@ -0,0 +1,83 @@
+package interceptor
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+const (
+	// redirectChain is the nat chain, which redirects the dns queries to the interceptor
+	redirectChain = "LITMUS-DNS"
+	// upstreamMark is the mark of the upstream queries of the interceptor
+	upstreamMark = 0x1d9c
+	// chainNotFound is the error message when the chain or the jump rule doesn't exist
+	chainNotFound = "No chain/target/match by that name"
+	// ruleNotFound is the error message when the rule doesn't exist
+	ruleNotFound = "does a matching rule exist"
+)
+
+// getBinaries returns the iptables binaries for the intercepted families
+func getBinaries(ipv6 bool) []string {
+	if ipv6 {
+		return []string{"iptables", "ip6tables"}
+	}
+	return []string{"iptables"}
+}
+
+// addRedirectRules redirects the outgoing udp and tcp dns queries of the family to the interceptor port
+// the queries of the interceptor itself are marked and skip the redirection
+func addRedirectRules(binary string, port int) error {
+	rules := [][]string{
+		{"-N", redirectChain},
+		{"-A", redirectChain, "-m", "mark", "--mark", fmt.Sprintf("%#x", upstreamMark), "-j", "RETURN"},
+		{"-A", redirectChain, "-p", "udp", "--dport", "53", "-j", "REDIRECT", "--to-ports", strconv.Itoa(port)},
+		{"-A", redirectChain, "-p", "tcp", "--dport", "53", "-j", "REDIRECT", "--to-ports", strconv.Itoa(port)},
+		{"-I", "OUTPUT", "-j", redirectChain},
+	}
+	for _, rule := range rules {
+		if out, err := runIPTables(binary, rule...); err != nil {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Reason: fmt.Sprintf("failed to add the %v redirect rules: %s", binary, out)}
+		}
+	}
+	log.Infof("[Chaos]: %v dns queries are redirected to the interceptor port %v", binary, port)
+	return nil
+}
+
+// redirectIPv6 removes the stale ip6tables redirect rules and redirects the ipv6 dns queries to the interceptor port
+func redirectIPv6(port int) error {
+	if err := removeRedirectRules([]string{"ip6tables"}); err != nil {
+		return err
+	}
+	return addRedirectRules("ip6tables", port)
+}
+
+// removeRedirectRules removes the redirect chain, it ignores the chain or the jump rule if it doesn't exist
+func removeRedirectRules(binaries []string) error {
+	for _, binary := range binaries {
+		for _, rule := range [][]string{{"-D", "OUTPUT", "-j", redirectChain}, {"-F", redirectChain}, {"-X", redirectChain}} {
+			if out, err := runIPTables(binary, rule...); err != nil {
+				if strings.Contains(out, chainNotFound) || strings.Contains(out, ruleNotFound) {
+					continue
+				}
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosRevert, Reason: fmt.Sprintf("failed to remove the %v redirect rules: %s", binary, out)}
+			}
+		}
+	}
+	log.Info("[Chaos]: dns redirect rules are removed")
+	return nil
+}
+
+// runIPTables runs the iptables command on the nat table, the interceptor is already inside the network namespace of the target
+func runIPTables(binary string, args ...string) (string, error) {
+	cmd := exec.Command(binary, append([]string{"-w", "-t", "nat"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Info(cmd.String())
+	}
+	return string(out), err
+}
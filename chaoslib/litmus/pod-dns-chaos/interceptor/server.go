@@ -0,0 +1,322 @@
+package interceptor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// upstreamTimeout is the timeout of a query to the upstream nameserver
+	upstreamTimeout = 5 * time.Second
+	// idleTimeout is the timeout of an idle tcp connection of the client
+	idleTimeout = 10 * time.Second
+	// maxMessageSize is the maximum size of a dns message
+	maxMessageSize = 65535
+)
+
+// server intercepts the redirected dns queries and applies the faults on them
+type server struct {
+	config    *Config
+	upstreams []string
+	counter   *counter
+	port      int
+	ipv6      bool
+	udpConns  []*net.UDPConn
+	listeners []net.Listener
+}
+
+// counter counts the decisions of the interceptor
+type counter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// newServer creates the interceptor server for the given upstream nameservers
+func newServer(config *Config, upstreams []string) *server {
+	return &server{
+		config:    config,
+		upstreams: upstreams,
+		counter:   &counter{counts: map[string]int{}},
+	}
+}
+
+// listen binds the udp and tcp sockets on the loopback addresses
+// the redirected queries are delivered on the loopback address of the family, so both of them are bound if possible
+func (s *server) listen() error {
+	var err error
+	// the port is allocated by the kernel for udp, retrying if the same port is not free for tcp
+	for retry := 0; retry < 5; retry++ {
+		if err = s.listenFamily("127.0.0.1", 0); err == nil {
+			break
+		}
+		s.close()
+	}
+	if err != nil {
+		return err
+	}
+
+	// ipv6 is optional, the pods may not have the ipv6 loopback address
+	if err := s.listenFamily("::1", s.port); err != nil {
+		log.Warnf("[Info]: Unable to listen on the ipv6 loopback address, only the ipv4 queries are intercepted, err: %v", err)
+		return nil
+	}
+	s.ipv6 = true
+	return nil
+}
+
+// listenFamily binds the udp and tcp sockets on the given address and port
+func (s *server) listenFamily(address string, port int) error {
+	udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(address), Port: port})
+	if err != nil {
+		return err
+	}
+	s.udpConns = append(s.udpConns, udpConn)
+	s.port = udpConn.LocalAddr().(*net.UDPAddr).Port
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(s.port)))
+	if err != nil {
+		return err
+	}
+	s.listeners = append(s.listeners, listener)
+	return nil
+}
+
+// serve starts serving the queries on all the bound sockets
+func (s *server) serve() {
+	for _, conn := range s.udpConns {
+		go s.serveUDP(conn)
+	}
+	for _, listener := range s.listeners {
+		go s.serveTCP(listener)
+	}
+}
+
+// close closes all the bound sockets
+func (s *server) close() {
+	for _, conn := range s.udpConns {
+		conn.Close()
+	}
+	for _, listener := range s.listeners {
+		listener.Close()
+	}
+	s.udpConns, s.listeners = nil, nil
+}
+
+// serveUDP serves the udp queries till the socket is closed
+func (s *server) serveUDP(conn *net.UDPConn) {
+	for {
+		buf := make([]byte, maxMessageSize)
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		go func() {
+			resp := s.handle(buf[:n], "udp")
+			if resp == nil {
+				return
+			}
+			if _, err := conn.WriteToUDP(resp, addr); err != nil {
+				log.Errorf("unable to write the udp response to %v, err: %v", addr, err)
+			}
+		}()
+	}
+}
+
+// serveTCP serves the tcp connections till the listener is closed
+func (s *server) serveTCP(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			for {
+				conn.SetDeadline(time.Now().Add(idleTimeout))
+				msg, err := readTCPMessage(conn)
+				if err != nil {
+					return
+				}
+				resp := s.handle(msg, "tcp")
+				if resp == nil {
+					return
+				}
+				if err := writeTCPMessage(conn, resp); err != nil {
+					log.Errorf("unable to write the tcp response to %v, err: %v", conn.RemoteAddr(), err)
+					return
+				}
+			}
+		}()
+	}
+}
+
+// handle decides the fault for the query and returns the response for the client
+// it returns nil, if the query should be dropped
+func (s *server) handle(msg []byte, network string) []byte {
+	q, err := parseQuery(msg)
+	if err != nil || len(q.questions) == 0 {
+		// the malformed queries are handed over to the upstream nameserver as it is
+		s.counter.inc(ActionForward)
+		return s.forwardOrDrop(msg, network)
+	}
+
+	question := q.questions[0]
+	d := s.config.decide(question.Name.String(), question.Type)
+	if d.Action == ActionTruncate && network == "tcp" {
+		// the tcp responses can't be truncated, the client is already retrying over tcp
+		d = decision{Action: ActionForward, Reason: "truncation not applicable over tcp"}
+	}
+	s.counter.inc(d.Action)
+
+	fields := logrus.Fields{
+		"Name":     question.Name.String(),
+		"Type":     question.Type.String(),
+		"Protocol": network,
+		"Action":   d.Action,
+		"Reason":   d.Reason,
+	}
+	if d.Spoof != "" {
+		fields["Spoof"] = d.Spoof
+	}
+	log.InfoWithValues("[Interceptor]: Query intercepted", fields)
+
+	var resp []byte
+	switch d.Action {
+	case ActionError:
+		resp, err = newResponse(q, dnsmessage.RCodeNameError, false)
+	case ActionServFail:
+		resp, err = newResponse(q, dnsmessage.RCodeServerFailure, false)
+	case ActionRefused:
+		resp, err = newResponse(q, dnsmessage.RCodeRefused, false)
+	case ActionTruncate:
+		resp, err = newResponse(q, dnsmessage.RCodeSuccess, true)
+	case ActionSpoof:
+		resp, err = s.spoof(msg, d.Spoof, network)
+	case ActionLatency:
+		time.Sleep(s.config.Latency)
+		resp, err = s.forward(msg, network)
+	default:
+		resp, err = s.forward(msg, network)
+	}
+	if err != nil {
+		log.Errorf("unable to resolve the %v query, err: %v", question.Name.String(), err)
+		// the client is informed about the failure, instead of waiting for the timeout
+		if resp, err = newResponse(q, dnsmessage.RCodeServerFailure, false); err != nil {
+			return nil
+		}
+	}
+	return resp
+}
+
+// spoof resolves the spoofed hostname and returns the answers for the original name
+func (s *server) spoof(msg []byte, spoof, network string) ([]byte, error) {
+	packed, original, spoofed, err := spoofQuery(msg, spoof)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.forward(packed, network)
+	if err != nil {
+		return nil, err
+	}
+	return restoreResponse(resp, original, spoofed)
+}
+
+// forwardOrDrop forwards the query, it returns nil if the query can't be forwarded
+func (s *server) forwardOrDrop(msg []byte, network string) []byte {
+	resp, err := s.forward(msg, network)
+	if err != nil {
+		log.Errorf("unable to forward the query, err: %v", err)
+		return nil
+	}
+	return resp
+}
+
+// forward sends the query to the upstream nameservers one by one, till any of them responds
+func (s *server) forward(msg []byte, network string) ([]byte, error) {
+	err := fmt.Errorf("no upstream nameserver found")
+	for _, upstream := range s.upstreams {
+		var resp []byte
+		if resp, err = exchange(msg, network, upstream); err == nil {
+			return resp, nil
+		}
+	}
+	return nil, err
+}
+
+// exchange sends the query to the upstream nameserver and returns the response
+// the socket is marked, so that the query is not redirected back to the interceptor
+func exchange(msg []byte, network, upstream string) ([]byte, error) {
+	dialer := net.Dialer{Timeout: upstreamTimeout, Control: markSocket}
+	conn, err := dialer.Dial(network, net.JoinHostPort(upstream, "53"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(upstreamTimeout))
+
+	if network == "tcp" {
+		if err := writeTCPMessage(conn, msg); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxMessageSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// readTCPMessage reads a length prefixed dns message from the tcp connection
+func readTCPMessage(conn net.Conn) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// writeTCPMessage writes a length prefixed dns message to the tcp connection
+func writeTCPMessage(conn net.Conn, msg []byte) error {
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := conn.Write(buf)
+	return err
+}
+
+// inc increments the count of the action
+func (c *counter) inc(action string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[action]++
+}
+
+// summary returns the counts of all the actions
+func (c *counter) summary() logrus.Fields {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fields := logrus.Fields{}
+	for action, count := range c.counts {
+		fields[action] = count
+	}
+	return fields
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/litmuschaos/litmus-go/chaoslib/litmus/pod-dns-chaos/interceptor"
 	"github.com/litmuschaos/litmus-go/pkg/cerrors"
 	"github.com/palantir/stacktrace"
 
@@ -26,6 +27,11 @@ import (
 //PrepareAndInjectChaos contains the preparation & injection steps
 func PrepareAndInjectChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails) error {
 
+	// validate the dns chaos tunables, before creating the helper pods
+	if _, err := interceptor.NewConfig(experimentsDetails); err != nil {
+		return stacktrace.Propagate(err, "could not validate the dns chaos tunables")
+	}
+
 	// Get the target pod details for the chaos execution
 	// if the target pod is not defined it will derive the random target pod list using pod affected percentage
 	if experimentsDetails.TargetPods == "" && chaosDetails.AppDetail == nil {
@@ -278,6 +284,9 @@ func getPodEnv(experimentsDetails *experimentTypes.ExperimentDetails, targets st
 		SetEnv("SPOOF_MAP", experimentsDetails.SpoofMap).
 		SetEnv("MATCH_SCHEME", experimentsDetails.MatchScheme).
 		SetEnv("CHAOS_TYPE", experimentsDetails.ChaosType).
+		SetEnv("RECORD_TYPES", experimentsDetails.RecordTypes).
+		SetEnv("FAILURE_PERCENTAGE", strconv.Itoa(experimentsDetails.FailurePercentage)).
+		SetEnv("DNS_LATENCY", strconv.Itoa(experimentsDetails.DNSLatency)).
 		SetEnv("INSTANCE_ID", experimentsDetails.InstanceID).
 		SetEnvFromDownwardAPI("v1", "metadata.name")
 
@@ -0,0 +1,410 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	clients "github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/events"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/pod-network-partition/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/result"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/common"
+	"github.com/palantir/stacktrace"
+	clientTypes "k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// egressChain and ingressChain are the dedicated chains, which contain all the partition rules
+	// so that the partition is reverted by removing the chains, without touching the rules of the target
+	egressChain  = "LITMUS-PARTITION-OUT"
+	ingressChain = "LITMUS-PARTITION-IN"
+	// chainNotFound and ruleNotFound are returned while removing the chains or the jump rules, which don't exist
+	chainNotFound = "No chain/target/match by that name"
+	ruleNotFound  = "does a matching rule exist"
+)
+
+var (
+	err           error
+	inject, abort chan os.Signal
+)
+
+// chain contains the details of the partition chain for the traffic direction
+type chain struct {
+	name string
+	// hook is the builtin chain, which jumps to the partition chain
+	hook string
+	// ipMatch is the direction of the peer ips inside the packets, it is -d for egress and -s for ingress
+	ipMatch string
+}
+
+var (
+	egress  = chain{name: egressChain, hook: "OUTPUT", ipMatch: "-d"}
+	ingress = chain{name: ingressChain, hook: "INPUT", ipMatch: "-s"}
+)
+
+// nodeIP is the ip of the node of the targets, which is exempted from the partition
+// so that the kubelet liveness and readiness probes of the targets keep passing, like the network policy based partition
+var nodeIP string
+
+// Helper injects the iptables based network partition
+func Helper(clients clients.ClientSets) {
+
+	experimentsDetails := experimentTypes.ExperimentDetails{}
+	eventsDetails := types.EventDetails{}
+	chaosDetails := types.ChaosDetails{}
+	resultDetails := types.ResultDetails{}
+
+	// inject channel is used to transmit signal notifications.
+	inject = make(chan os.Signal, 1)
+	// Catch and relay certain signal(s) to inject channel.
+	signal.Notify(inject, os.Interrupt, syscall.SIGTERM)
+
+	// abort channel is used to transmit signal notifications.
+	abort = make(chan os.Signal, 1)
+	// Catch and relay certain signal(s) to abort channel.
+	signal.Notify(abort, os.Interrupt, syscall.SIGTERM)
+
+	//Fetching all the ENV passed for the helper pod
+	log.Info("[PreReq]: Getting the ENV variables")
+	getENV(&experimentsDetails)
+
+	// Initialise the chaos attributes
+	types.InitialiseChaosVariables(&chaosDetails)
+	chaosDetails.Phase = types.ChaosInjectPhase
+
+	// Initialise Chaos Result Parameters
+	types.SetResultAttributes(&resultDetails, chaosDetails)
+
+	// Set the chaos result uid
+	result.SetResultUID(&resultDetails, clients, &chaosDetails)
+
+	if err := preparePartitionChaos(&experimentsDetails, clients, &eventsDetails, &chaosDetails, &resultDetails); err != nil {
+		// update failstep inside chaosresult
+		if resultErr := result.UpdateFailedStepFromHelper(&resultDetails, &chaosDetails, clients, err); resultErr != nil {
+			log.Fatalf("helper pod failed, err: %v, resultErr: %v", err, resultErr)
+		}
+		log.Fatalf("helper pod failed, err: %v", err)
+	}
+}
+
+// preparePartitionChaos contains the preparation steps before chaos injection
+func preparePartitionChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails) error {
+
+	targetEnv := os.Getenv("TARGETS")
+	if targetEnv == "" {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeHelper, Source: chaosDetails.ChaosPodName, Reason: "no target found, provide atleast one target"}
+	}
+
+	var targets []targetDetails
+
+	for _, t := range strings.Split(targetEnv, ";") {
+		target := strings.Split(t, ":")
+		if len(target) != 3 {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeHelper, Source: chaosDetails.ChaosPodName, Reason: fmt.Sprintf("unsupported target format: '%v'", t)}
+		}
+		td := targetDetails{
+			Name:            target[0],
+			Namespace:       target[1],
+			TargetContainer: target[2],
+			Source:          chaosDetails.ChaosPodName,
+		}
+
+		td.ContainerId, err = common.GetRuntimeBasedContainerID(experimentsDetails.ContainerRuntime, experimentsDetails.SocketPath, td.Name, td.Namespace, td.TargetContainer, clients, td.Source)
+		if err != nil {
+			return stacktrace.Propagate(err, "could not get container id")
+		}
+
+		// extract out the pid of the target container
+		td.Pid, err = common.GetPauseAndSandboxPID(experimentsDetails.ContainerRuntime, td.ContainerId, experimentsDetails.SocketPath, td.Source)
+		if err != nil {
+			return stacktrace.Propagate(err, "could not get container pid")
+		}
+
+		targets = append(targets, td)
+	}
+
+	peers := getList(experimentsDetails.PeerIPs)
+	ports := getList(experimentsDetails.PartitionPorts)
+	chains := getChains(experimentsDetails.PolicyTypes)
+	binaries := getBinaries(peers)
+
+	// watching for the abort signal and revert the chaos
+	go abortWatcher(targets, chains, binaries, resultDetails.Name, chaosDetails.ChaosNamespace)
+
+	select {
+	case <-inject:
+		// stopping the chaos execution, if abort signal received
+		os.Exit(1)
+	default:
+	}
+
+	for _, t := range targets {
+		// installing the partition rules inside the network namespace of the target container
+		if err = injectChaos(t, chains, binaries, peers, ports, experimentsDetails.Action); err != nil {
+			if killed, revertErr := removeChains(t, chains, binaries); !killed && revertErr != nil {
+				return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s,%s]", stacktrace.RootCause(err).Error(), stacktrace.RootCause(revertErr).Error())}
+			}
+			return stacktrace.Propagate(err, "could not inject chaos")
+		}
+		log.Infof("successfully injected chaos on target: {name: %s, namespace: %v, container: %v}", t.Name, t.Namespace, t.TargetContainer)
+		if err = result.AnnotateChaosResult(resultDetails.Name, chaosDetails.ChaosNamespace, "injected", "pod", t.Name); err != nil {
+			if killed, revertErr := removeChains(t, chains, binaries); !killed && revertErr != nil {
+				return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s,%s]", stacktrace.RootCause(err).Error(), stacktrace.RootCause(revertErr).Error())}
+			}
+			return stacktrace.Propagate(err, "could not annotate chaosresult")
+		}
+	}
+
+	if experimentsDetails.EngineName != "" {
+		msg := "Injected " + experimentsDetails.ExperimentName + " chaos on application pods"
+		types.SetEngineEventAttributes(eventsDetails, types.ChaosInject, msg, "Normal", chaosDetails)
+		events.GenerateEvents(eventsDetails, clients, chaosDetails, "ChaosEngine")
+	}
+
+	log.Infof("[Chaos]: Waiting for %vs", experimentsDetails.ChaosDuration)
+
+	common.WaitForDuration(experimentsDetails.ChaosDuration)
+
+	log.Info("[Chaos]: duration is over, reverting chaos")
+
+	var errList []string
+	for _, t := range targets {
+		// removing the partition chains after chaos injection
+		killed, err := removeChains(t, chains, binaries)
+		if !killed && err != nil {
+			errList = append(errList, err.Error())
+			continue
+		}
+		if err = result.AnnotateChaosResult(resultDetails.Name, chaosDetails.ChaosNamespace, "reverted", "pod", t.Name); err != nil {
+			errList = append(errList, err.Error())
+		}
+	}
+
+	if len(errList) != 0 {
+		return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s]", strings.Join(errList, ","))}
+	}
+	return nil
+}
+
+// injectChaos creates the partition chains inside the network namespace of the target container
+// the stale chains of an earlier aborted run are removed first, as the chains can't be created if they already exist
+// the rules are added to the chains before the chains are hooked, so the partition is applied at once
+func injectChaos(target targetDetails, chains []chain, binaries []string, peers, ports []string, action string) error {
+	if err := deleteChains(target, chains, binaries); err != nil {
+		return stacktrace.Propagate(err, "could not remove the stale partition rules")
+	}
+	for _, binary := range binaries {
+		for _, c := range chains {
+			rules, err := getRules(c, binary, peers, ports, action)
+			if err != nil {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Source: target.Source, Target: fmt.Sprintf("{podName: %s, namespace: %s, container: %s}", target.Name, target.Namespace, target.TargetContainer), Reason: err.Error()}
+			}
+			commands := []string{fmt.Sprintf("-N %v", c.name)}
+			for _, rule := range rules {
+				commands = append(commands, fmt.Sprintf("-A %v %v", c.name, rule))
+			}
+			commands = append(commands, fmt.Sprintf("-I %v -j %v", c.hook, c.name))
+
+			for _, command := range commands {
+				cmd := fmt.Sprintf("sudo nsenter -t %v -n %v -w %v", target.Pid, binary, command)
+				log.Info(cmd)
+				if err := common.RunBashCommand(cmd, fmt.Sprintf("failed to create the %v partition rules", binary), target.Source); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	log.Infof("chaos injected successfully on {pod: %v, container: %v}", target.Name, target.TargetContainer)
+	return nil
+}
+
+// getRules derives the rules of the chain for the peers of the given family
+// it matches all the traffic of the direction, if neither peers nor ports are provided
+// the loopback traffic and the traffic of the node ip, if exempted, are returned from the chain before the partition rules
+// it returns an error for the malformed ports, otherwise the rules would partition all the ports
+func getRules(c chain, binary string, peers, ports []string, action string) ([]string, error) {
+	exemptions := []string{"-i lo -j RETURN"}
+	if c.hook == "OUTPUT" {
+		exemptions = []string{"-o lo -j RETURN"}
+	}
+	if nodeIP != "" && getBinary(nodeIP) == binary {
+		exemptions = append(exemptions, fmt.Sprintf("%v %v -j RETURN", c.ipMatch, nodeIP))
+	}
+
+	var ipMatches, portMatches []string
+	for _, peer := range peers {
+		if getBinary(peer) == binary {
+			ipMatches = append(ipMatches, fmt.Sprintf("%v %v", c.ipMatch, peer))
+		}
+	}
+	if len(ipMatches) == 0 {
+		ipMatches = []string{""}
+	}
+	for _, port := range ports {
+		// ports are in <protocol>:<port> format
+		protocolPort := strings.Split(port, ":")
+		if len(protocolPort) != 2 || protocolPort[0] == "" || protocolPort[1] == "" {
+			return nil, fmt.Errorf("'%v' partition port is invalid, it should be in <protocol>:<port> format", port)
+		}
+		portMatch := fmt.Sprintf("-p %v --dport %v", protocolPort[0], protocolPort[1])
+		if action == "REJECT" && protocolPort[0] == "tcp" {
+			// the tcp connections are reset, instead of the default icmp unreachable reply
+			portMatch += " -j REJECT --reject-with tcp-reset"
+		} else {
+			portMatch += " -j " + action
+		}
+		portMatches = append(portMatches, portMatch)
+	}
+	if len(portMatches) == 0 {
+		portMatches = []string{"-j " + action}
+	}
+
+	rules := exemptions
+	for _, ipMatch := range ipMatches {
+		for _, portMatch := range portMatches {
+			rules = append(rules, strings.TrimSpace(ipMatch+" "+portMatch))
+		}
+	}
+	return rules, nil
+}
+
+// removeChains removes the partition chains from the network namespace of the target container
+// it returns true, if the chains are removed or they have been already removed
+func removeChains(target targetDetails, chains []chain, binaries []string) (bool, error) {
+	if err := deleteChains(target, chains, binaries); err != nil {
+		return false, err
+	}
+	log.Infof("successfully reverted chaos on target: {name: %s, namespace: %v, container: %v}", target.Name, target.Namespace, target.TargetContainer)
+	return true, nil
+}
+
+// deleteChains unhooks, flushes and deletes the partition chains
+// it ignores the chains or the jump rules, which don't exist inside the target container
+func deleteChains(target targetDetails, chains []chain, binaries []string) error {
+	for _, binary := range binaries {
+		for _, c := range chains {
+			for _, command := range []string{fmt.Sprintf("-D %v -j %v", c.hook, c.name), fmt.Sprintf("-F %v", c.name), fmt.Sprintf("-X %v", c.name)} {
+				cmd := exec.Command("/bin/bash", "-c", fmt.Sprintf("sudo nsenter -t %v -n %v -w %v", target.Pid, binary, command))
+				out, err := cmd.CombinedOutput()
+				if err != nil {
+					log.Info(cmd.String())
+					// ignoring err if the chain or the jump rule doesn't exist inside the target container
+					if strings.Contains(string(out), chainNotFound) || strings.Contains(string(out), ruleNotFound) {
+						continue
+					}
+					log.Error(err.Error())
+					return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosRevert, Source: target.Source, Target: fmt.Sprintf("{podName: %s, namespace: %s, container: %s}", target.Name, target.Namespace, target.TargetContainer), Reason: fmt.Sprintf("failed to remove the %v partition rules: %s", binary, string(out))}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getChains returns the chains for the policy types
+func getChains(policyTypes string) []chain {
+	switch strings.ToLower(policyTypes) {
+	case "ingress":
+		return []chain{ingress}
+	case "egress":
+		return []chain{egress}
+	default:
+		return []chain{egress, ingress}
+	}
+}
+
+// getBinaries returns the iptables binaries for the families of the peers
+// both the families are partitioned, if the peers are not provided
+func getBinaries(peers []string) []string {
+	if len(peers) == 0 {
+		return []string{"iptables", "ip6tables"}
+	}
+	var binaries []string
+	for _, peer := range peers {
+		if binary := getBinary(peer); !common.Contains(binary, binaries) {
+			binaries = append(binaries, binary)
+		}
+	}
+	return binaries
+}
+
+// getBinary returns the iptables binary for the family of the ip or cidr
+func getBinary(ip string) string {
+	if strings.Contains(ip, ":") {
+		return "ip6tables"
+	}
+	return "iptables"
+}
+
+// getList returns the values of the comma separated list
+func getList(values string) []string {
+	if strings.TrimSpace(values) == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(values), ",")
+}
+
+type targetDetails struct {
+	Name            string
+	Namespace       string
+	TargetContainer string
+	ContainerId     string
+	Pid             int
+	Source          string
+}
+
+// getENV fetches all the env variables from the runner pod
+func getENV(experimentDetails *experimentTypes.ExperimentDetails) {
+	experimentDetails.ExperimentName = types.Getenv("EXPERIMENT_NAME", "")
+	experimentDetails.InstanceID = types.Getenv("INSTANCE_ID", "")
+	experimentDetails.ChaosDuration, _ = strconv.Atoi(types.Getenv("TOTAL_CHAOS_DURATION", ""))
+	experimentDetails.ChaosNamespace = types.Getenv("CHAOS_NAMESPACE", "litmus")
+	experimentDetails.EngineName = types.Getenv("CHAOSENGINE", "")
+	experimentDetails.ChaosUID = clientTypes.UID(types.Getenv("CHAOS_UID", ""))
+	experimentDetails.ChaosPodName = types.Getenv("POD_NAME", "")
+	experimentDetails.ContainerRuntime = types.Getenv("CONTAINER_RUNTIME", "")
+	experimentDetails.SocketPath = types.Getenv("SOCKET_PATH", "")
+	experimentDetails.PolicyTypes = types.Getenv("POLICY_TYPES", "all")
+	experimentDetails.Action = types.Getenv("ACTION", "DROP")
+	experimentDetails.PeerIPs = types.Getenv("PEER_IPS", "")
+	experimentDetails.PartitionPorts = types.Getenv("PARTITION_PORTS", "")
+	experimentDetails.ExemptNodeIP = types.Getenv("EXEMPT_NODE_IP", "true")
+	if experimentDetails.ExemptNodeIP == "true" {
+		nodeIP = strings.TrimSpace(types.Getenv("NODE_IP", ""))
+	}
+}
+
+// abortWatcher continuously watch for the abort signals
+func abortWatcher(targets []targetDetails, chains []chain, binaries []string, resultName, chaosNS string) {
+
+	<-abort
+	log.Info("[Chaos]: Killing process started because of terminated signal received")
+	log.Info("Chaos Revert Started")
+	// retry thrice for the chaos revert
+	retry := 3
+	for retry > 0 {
+		for _, t := range targets {
+			killed, err := removeChains(t, chains, binaries)
+			if err != nil && !killed {
+				log.Errorf("unable to remove the partition rules, err :%v", err)
+				continue
+			}
+			if err = result.AnnotateChaosResult(resultName, chaosNS, "reverted", "pod", t.Name); err != nil {
+				log.Errorf("unable to annotate the chaosresult, err :%v", err)
+			}
+		}
+		retry--
+		time.Sleep(1 * time.Second)
+	}
+	log.Info("Chaos Revert Completed")
+	os.Exit(1)
+}
@@ -0,0 +1,363 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	network_chaos "github.com/litmuschaos/litmus-go/chaoslib/litmus/network-chaos/lib"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/pod-network-partition/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/probe"
+	"github.com/litmuschaos/litmus-go/pkg/status"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/common"
+	"github.com/litmuschaos/litmus-go/pkg/utils/stringutils"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// PrepareAndInjectIPTablesChaos contains the preparation & injection steps of the iptables based partition
+// it creates the helper pods, which install the iptables rules inside the network namespace of the target pods
+// so the partition doesn't depend on the network policy support of the cni
+func PrepareAndInjectIPTablesChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails) error {
+
+	// validate the appLabels
+	if chaosDetails.AppDetail == nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeTargetSelection, Reason: "provide the appLabel"}
+	}
+
+	switch strings.ToUpper(experimentsDetails.Action) {
+	case "DROP", "REJECT":
+		experimentsDetails.Action = strings.ToUpper(experimentsDetails.Action)
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' action is not supported, it should be one of DROP or REJECT", experimentsDetails.Action)}
+	}
+
+	// Get the target pod details for the chaos execution
+	targetPodList, err := common.GetPodList("", 100, clients, chaosDetails)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not get target pods")
+	}
+
+	// collect the peers and ports of the partition
+	np := initialize().
+		setPolicy(experimentsDetails.PolicyTypes).
+		setPodSelector(experimentsDetails.PodSelector).
+		setNamespaceSelector(experimentsDetails.NamespaceSelector)
+	if err := np.setPort(experimentsDetails.PORTS); err != nil {
+		return stacktrace.Propagate(err, "could not set port")
+	}
+	experimentsDetails.PartitionPorts = getPartitionPorts(np)
+
+	peerIPs, err := getPeerIPs(experimentsDetails, np, clients)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not get peer ips")
+	}
+	experimentsDetails.PeerIPs = strings.Join(peerIPs, ",")
+
+	//DISPLAY THE PARTITION DETAILS
+	log.InfoWithValues("The partition details are as follows", logrus.Fields{
+		"Policy Type":       experimentsDetails.PolicyTypes,
+		"Action":            experimentsDetails.Action,
+		"PodSelector":       np.PodSelector,
+		"NamespaceSelector": np.NamespaceSelector,
+		"Peer IPs":          experimentsDetails.PeerIPs,
+		"Ports":             experimentsDetails.PartitionPorts,
+	})
+
+	//Waiting for the ramp time before chaos injection
+	if experimentsDetails.RampTime != 0 {
+		log.Infof("[Ramp]: Waiting for the %vs ramp time before injecting chaos", experimentsDetails.RampTime)
+		common.WaitForDuration(experimentsDetails.RampTime)
+	}
+
+	// Getting the serviceAccountName, need permission inside helper pod to create the events
+	if experimentsDetails.ChaosServiceAccount == "" {
+		experimentsDetails.ChaosServiceAccount, err = common.GetServiceAccount(experimentsDetails.ChaosNamespace, experimentsDetails.ChaosPodName, clients)
+		if err != nil {
+			return stacktrace.Propagate(err, "could not get experiment service account")
+		}
+	}
+
+	if experimentsDetails.EngineName != "" {
+		if err := common.SetHelperData(chaosDetails, experimentsDetails.SetHelperData, clients); err != nil {
+			return stacktrace.Propagate(err, "could not set helper data")
+		}
+	}
+
+	// run the probes during chaos
+	if len(resultDetails.ProbeDetails) != 0 {
+		if err := probe.RunProbes(chaosDetails, clients, resultDetails, "DuringChaos", eventsDetails); err != nil {
+			return err
+		}
+	}
+
+	groups := []partitionGroup{{pods: targetPodList.Items, peerIPs: experimentsDetails.PeerIPs}}
+	if err := injectPartitionChaos(experimentsDetails, groups, false, clients, chaosDetails); err != nil {
+		return stacktrace.Propagate(err, "could not run the partition chaos")
+	}
+
+	//Waiting for the ramp time after chaos injection
+	if experimentsDetails.RampTime != 0 {
+		log.Infof("[Ramp]: Waiting for the %vs ramp time after injecting chaos", experimentsDetails.RampTime)
+		common.WaitForDuration(experimentsDetails.RampTime)
+	}
+	return nil
+}
+
+// partitionGroup contains the target pods, which are partitioned from the same peers
+type partitionGroup struct {
+	pods    []apiv1.Pod
+	peerIPs string
+}
+
+// injectPartitionChaos creates the helper pods for all the target pods at once, one helper pod per node for every group
+// and waits till the completion of the helper pods
+// if revertAll is set, all the helper pods are removed on failure irrespective of the job cleanup policy
+// so that every helper pod reverts its rules and the partition is not left partially applied
+func injectPartitionChaos(experimentsDetails *experimentTypes.ExperimentDetails, groups []partitionGroup, revertAll bool, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+	runID := stringutils.GetRunID()
+	appLabel := fmt.Sprintf("app=%s-helper-%s", experimentsDetails.ExperimentName, runID)
+
+	cleanup := func() {
+		if !revertAll {
+			common.DeleteAllHelperPodBasedOnJobCleanupPolicy(appLabel, chaosDetails, clients)
+			return
+		}
+		if err := common.DeleteAllPod(appLabel, experimentsDetails.ChaosNamespace, chaosDetails.Timeout, chaosDetails.Delay, clients); err != nil {
+			log.Errorf("unable to delete the helper pods, err: %v", err)
+		}
+	}
+
+	for _, group := range groups {
+		targets := map[string][]string{}
+		for _, pod := range group.pods {
+			targetContainer := experimentsDetails.TargetContainer
+			if targetContainer == "" {
+				targetContainer = pod.Spec.Containers[0].Name
+			}
+			targets[pod.Spec.NodeName] = append(targets[pod.Spec.NodeName], fmt.Sprintf("%s:%s:%s", pod.Name, pod.Namespace, targetContainer))
+		}
+
+		for node, targetsPerNode := range targets {
+			if err := createPartitionHelperPod(experimentsDetails, clients, chaosDetails, strings.Join(targetsPerNode, ";"), group.peerIPs, node, runID); err != nil {
+				if revertAll {
+					cleanup()
+				}
+				return stacktrace.Propagate(err, "could not create helper pod")
+			}
+		}
+	}
+
+	//checking the status of the helper pods, wait till the pod comes to running state else fail the experiment
+	log.Info("[Status]: Checking the status of the helper pods")
+	if err := status.CheckHelperStatus(experimentsDetails.ChaosNamespace, appLabel, experimentsDetails.Timeout, experimentsDetails.Delay, clients); err != nil {
+		cleanup()
+		return stacktrace.Propagate(err, "could not check helper status")
+	}
+
+	// Wait till the completion of the helper pod
+	// set an upper limit for the waiting time
+	log.Info("[Wait]: waiting till the completion of the helper pod")
+	podStatus, err := status.WaitForCompletion(experimentsDetails.ChaosNamespace, appLabel, clients, experimentsDetails.ChaosDuration+experimentsDetails.Timeout, common.GetContainerNames(chaosDetails)...)
+	if err != nil || podStatus == "Failed" {
+		cleanup()
+		return common.HelperFailedError(err, appLabel, chaosDetails.ChaosNamespace, true)
+	}
+
+	//Deleting all the helper pod for network partition chaos
+	log.Info("[Cleanup]: Deleting all the helper pod")
+	if err := common.DeleteAllPod(appLabel, experimentsDetails.ChaosNamespace, chaosDetails.Timeout, chaosDetails.Delay, clients); err != nil {
+		return stacktrace.Propagate(err, "could not delete helper pod(s)")
+	}
+	return nil
+}
+
+// getPeerIPs returns the ips of the peers, derived from the destination ips, hosts and the pod and namespace selectors
+// the pod selector selects the pods inside the application namespace, if the namespace selector is not provided
+// it returns no ips, if none of the peers are provided, which partitions the targets from all the peers
+func getPeerIPs(experimentsDetails *experimentTypes.ExperimentDetails, np *NetworkPolicy, clients clients.ClientSets) ([]string, error) {
+	destinationIPs, err := network_chaos.GetTargetIps(experimentsDetails.DestinationIPs, experimentsDetails.DestinationHosts, clients, false)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "could not get destination ips")
+	}
+	var ips []string
+	if destinationIPs != "" {
+		ips = strings.Split(destinationIPs, ",")
+	}
+
+	if len(np.PodSelector) == 0 && len(np.NamespaceSelector) == 0 {
+		return uniqueValues(ips), nil
+	}
+
+	namespaces := []string{experimentsDetails.AppNS}
+	if len(np.NamespaceSelector) != 0 {
+		selector := labels.SelectorFromSet(np.NamespaceSelector).String()
+		nsList, err := clients.KubeClient.CoreV1().Namespaces().List(context.Background(), v1.ListOptions{LabelSelector: selector})
+		if k8serrors.IsForbidden(err) {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{namespaceSelector: %s}", selector), Reason: "the namespace selector requires the cluster-wide list permission on the namespaces and pods, apply the pod-network-partition ClusterRole"}
+		}
+		if err != nil {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{namespaceSelector: %s}", selector), Reason: fmt.Sprintf("failed to list the namespaces: %s", err.Error())}
+		}
+		namespaces = nil
+		for _, ns := range nsList.Items {
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+
+	podSelector := labels.SelectorFromSet(np.PodSelector).String()
+	peers := 0
+	for _, ns := range namespaces {
+		pods, err := clients.KubeClient.CoreV1().Pods(ns).List(context.Background(), v1.ListOptions{LabelSelector: podSelector})
+		if err != nil {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{podSelector: %s, namespace: %s}", podSelector, ns), Reason: fmt.Sprintf("failed to list the peer pods: %s", err.Error())}
+		}
+		for _, pod := range pods.Items {
+			for _, ip := range pod.Status.PodIPs {
+				ips = append(ips, ip.IP)
+				peers++
+			}
+		}
+	}
+
+	// the selectors without any peer would otherwise partition the targets from all the peers
+	if peers == 0 {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{podSelector: %s, namespaces: %v}", podSelector, namespaces), Reason: "no peer pod found with the matching selectors"}
+	}
+	return uniqueValues(ips), nil
+}
+
+// getPartitionPorts returns the comma separated ports in <protocol>:<port> format
+func getPartitionPorts(np *NetworkPolicy) string {
+	var ports []string
+	for _, p := range np.Ports {
+		ports = append(ports, fmt.Sprintf("%s:%s", strings.ToLower(string(*p.Protocol)), p.Port.String()))
+	}
+	return strings.Join(ports, ",")
+}
+
+// uniqueValues removes the duplicate and empty values from the list
+func uniqueValues(values []string) []string {
+	var unique []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !common.Contains(v, unique) {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// createPartitionHelperPod derive the attributes for helper pod and create the helper pod
+func createPartitionHelperPod(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails, targets, peerIPs, nodeName, runID string) error {
+
+	privilegedEnable := true
+	terminationGracePeriodSeconds := int64(experimentsDetails.TerminationGracePeriodSeconds)
+
+	helperPod := &apiv1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			GenerateName: experimentsDetails.ExperimentName + "-helper-",
+			Namespace:    experimentsDetails.ChaosNamespace,
+			Labels:       common.GetHelperLabels(chaosDetails.Labels, runID, experimentsDetails.ExperimentName),
+			Annotations:  chaosDetails.Annotations,
+		},
+		Spec: apiv1.PodSpec{
+			HostPID:                       true,
+			TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+			ImagePullSecrets:              chaosDetails.ImagePullSecrets,
+			ServiceAccountName:            experimentsDetails.ChaosServiceAccount,
+			RestartPolicy:                 apiv1.RestartPolicyNever,
+			NodeName:                      nodeName,
+			Volumes: []apiv1.Volume{
+				{
+					Name: "cri-socket",
+					VolumeSource: apiv1.VolumeSource{
+						HostPath: &apiv1.HostPathVolumeSource{
+							Path: experimentsDetails.SocketPath,
+						},
+					},
+				},
+			},
+
+			Containers: []apiv1.Container{
+				{
+					Name:            experimentsDetails.ExperimentName,
+					Image:           experimentsDetails.LIBImage,
+					ImagePullPolicy: apiv1.PullPolicy(experimentsDetails.LIBImagePullPolicy),
+					Command: []string{
+						"/bin/bash",
+					},
+					Args: []string{
+						"-c",
+						"./helpers -name network-partition",
+					},
+					Resources: chaosDetails.Resources,
+					Env:       getPartitionPodEnv(experimentsDetails, targets, peerIPs),
+					VolumeMounts: []apiv1.VolumeMount{
+						{
+							Name:      "cri-socket",
+							MountPath: experimentsDetails.SocketPath,
+						},
+					},
+					SecurityContext: &apiv1.SecurityContext{
+						Privileged: &privilegedEnable,
+						Capabilities: &apiv1.Capabilities{
+							Add: []apiv1.Capability{
+								"NET_ADMIN",
+								"SYS_ADMIN",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if len(chaosDetails.SideCar) != 0 {
+		helperPod.Spec.Containers = append(helperPod.Spec.Containers, common.BuildSidecar(chaosDetails)...)
+		helperPod.Spec.Volumes = append(helperPod.Spec.Volumes, common.GetSidecarVolumes(chaosDetails)...)
+	}
+
+	_, err := clients.KubeClient.CoreV1().Pods(experimentsDetails.ChaosNamespace).Create(context.Background(), helperPod, v1.CreateOptions{})
+	if err != nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("unable to create helper pod: %s", err.Error())}
+	}
+	return nil
+}
+
+// getPartitionPodEnv derive all the env required for the helper pod
+func getPartitionPodEnv(experimentsDetails *experimentTypes.ExperimentDetails, targets, peerIPs string) []apiv1.EnvVar {
+
+	var envDetails common.ENVDetails
+	envDetails.SetEnv("TARGETS", targets).
+		SetEnv("TOTAL_CHAOS_DURATION", strconv.Itoa(experimentsDetails.ChaosDuration)).
+		SetEnv("CHAOS_NAMESPACE", experimentsDetails.ChaosNamespace).
+		SetEnv("CHAOSENGINE", experimentsDetails.EngineName).
+		SetEnv("CHAOS_UID", string(experimentsDetails.ChaosUID)).
+		SetEnv("CONTAINER_RUNTIME", experimentsDetails.ContainerRuntime).
+		SetEnv("EXPERIMENT_NAME", experimentsDetails.ExperimentName).
+		SetEnv("SOCKET_PATH", experimentsDetails.SocketPath).
+		SetEnv("INSTANCE_ID", experimentsDetails.InstanceID).
+		SetEnv("POLICY_TYPES", experimentsDetails.PolicyTypes).
+		SetEnv("ACTION", experimentsDetails.Action).
+		SetEnv("PEER_IPS", peerIPs).
+		SetEnv("PARTITION_PORTS", experimentsDetails.PartitionPorts).
+		SetEnv("EXEMPT_NODE_IP", experimentsDetails.ExemptNodeIP).
+		SetEnvFromDownwardAPI("v1", "metadata.name")
+
+	// the helper pod runs on the node of the targets, so its host ip is the node ip of the targets
+	envDetails.ENV = append(envDetails.ENV, apiv1.EnvVar{
+		Name:      "NODE_IP",
+		ValueFrom: &apiv1.EnvVarSource{FieldRef: &apiv1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "status.hostIP"}},
+	})
+	return envDetails.ENV
+}
@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	experimentTypes "github.com/litmuschaos/litmus-go/pkg/generic/pod-network-partition/types"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/probe"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/common"
+	"github.com/palantir/stacktrace"
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PrepareAndInjectSplitBrainChaos contains the preparation & injection steps of the split brain partition
+// it isolates the pods of both the groups from each other in both the directions, rest of the traffic is left intact
+// the rules are installed on both the sides, so the groups stay isolated even if one side is reverted early
+func PrepareAndInjectSplitBrainChaos(experimentsDetails *experimentTypes.ExperimentDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, eventsDetails *types.EventDetails, chaosDetails *types.ChaosDetails) error {
+
+	if strings.TrimSpace(experimentsDetails.GroupALabels) == "" || strings.TrimSpace(experimentsDetails.GroupBLabels) == "" {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeTargetSelection, Reason: "provide the GROUP_A_LABELS and GROUP_B_LABELS to select the groups"}
+	}
+
+	switch strings.ToUpper(experimentsDetails.Action) {
+	case "DROP", "REJECT":
+		experimentsDetails.Action = strings.ToUpper(experimentsDetails.Action)
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' action is not supported, it should be one of DROP or REJECT", experimentsDetails.Action)}
+	}
+
+	// the groups are isolated in both the directions
+	experimentsDetails.PolicyTypes = "all"
+
+	np := initialize()
+	if err := np.setPort(experimentsDetails.PORTS); err != nil {
+		return stacktrace.Propagate(err, "could not set port")
+	}
+	experimentsDetails.PartitionPorts = getPartitionPorts(np)
+
+	namespace := experimentsDetails.AppNS
+	if namespace == "" {
+		namespace = experimentsDetails.ChaosNamespace
+	}
+
+	groupA, err := getGroupPods(experimentsDetails.GroupALabels, namespace, clients)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not get the pods of group a")
+	}
+	groupB, err := getGroupPods(experimentsDetails.GroupBLabels, namespace, clients)
+	if err != nil {
+		return stacktrace.Propagate(err, "could not get the pods of group b")
+	}
+
+	// a pod can't be isolated from itself, so the groups must be disjoint
+	for _, a := range groupA {
+		for _, b := range groupB {
+			if a.UID == b.UID {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeTargetSelection, Target: fmt.Sprintf("{podName: %s, namespace: %s}", a.Name, a.Namespace), Reason: "pod is selected by both the groups, provide the disjoint groups"}
+			}
+		}
+	}
+
+	groupAIPs, groupBIPs := getGroupIPs(groupA), getGroupIPs(groupB)
+
+	//DISPLAY THE SPLIT BRAIN DETAILS
+	log.InfoWithValues("The split brain details are as follows", logrus.Fields{
+		"Group A":     getPodNames(groupA),
+		"Group B":     getPodNames(groupB),
+		"Group A IPs": groupAIPs,
+		"Group B IPs": groupBIPs,
+		"Action":      experimentsDetails.Action,
+		"Ports":       experimentsDetails.PartitionPorts,
+	})
+
+	//Waiting for the ramp time before chaos injection
+	if experimentsDetails.RampTime != 0 {
+		log.Infof("[Ramp]: Waiting for the %vs ramp time before injecting chaos", experimentsDetails.RampTime)
+		common.WaitForDuration(experimentsDetails.RampTime)
+	}
+
+	// Getting the serviceAccountName, need permission inside helper pod to create the events
+	if experimentsDetails.ChaosServiceAccount == "" {
+		experimentsDetails.ChaosServiceAccount, err = common.GetServiceAccount(experimentsDetails.ChaosNamespace, experimentsDetails.ChaosPodName, clients)
+		if err != nil {
+			return stacktrace.Propagate(err, "could not get experiment service account")
+		}
+	}
+
+	if experimentsDetails.EngineName != "" {
+		if err := common.SetHelperData(chaosDetails, experimentsDetails.SetHelperData, clients); err != nil {
+			return stacktrace.Propagate(err, "could not set helper data")
+		}
+	}
+
+	// run the probes during chaos
+	if len(resultDetails.ProbeDetails) != 0 {
+		if err := probe.RunProbes(chaosDetails, clients, resultDetails, "DuringChaos", eventsDetails); err != nil {
+			return err
+		}
+	}
+
+	// the helper pods of both the groups are created at once and all of them are reverted, if any of them fails
+	groups := []partitionGroup{
+		{pods: groupA, peerIPs: strings.Join(groupBIPs, ",")},
+		{pods: groupB, peerIPs: strings.Join(groupAIPs, ",")},
+	}
+	if err := injectPartitionChaos(experimentsDetails, groups, true, clients, chaosDetails); err != nil {
+		return stacktrace.Propagate(err, "could not run the split brain chaos")
+	}
+
+	//Waiting for the ramp time after chaos injection
+	if experimentsDetails.RampTime != 0 {
+		log.Infof("[Ramp]: Waiting for the %vs ramp time after injecting chaos", experimentsDetails.RampTime)
+		common.WaitForDuration(experimentsDetails.RampTime)
+	}
+	return nil
+}
+
+// getGroupPods returns the running pods of the group, which match the given labels
+func getGroupPods(groupLabels, namespace string, clients clients.ClientSets) ([]apiv1.Pod, error) {
+	podList, err := clients.KubeClient.CoreV1().Pods(namespace).List(context.Background(), v1.ListOptions{LabelSelector: groupLabels})
+	if err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeTargetSelection, Target: fmt.Sprintf("{podLabels: %s, namespace: %s}", groupLabels, namespace), Reason: fmt.Sprintf("failed to list the pods: %s", err.Error())}
+	}
+
+	var pods []apiv1.Pod
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != apiv1.PodRunning || len(pod.Status.PodIPs) == 0 {
+			log.Warnf("[Info]: Skipping the %v pod of the group, as it is not running", pod.Name)
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	if len(pods) == 0 {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeTargetSelection, Target: fmt.Sprintf("{podLabels: %s, namespace: %s}", groupLabels, namespace), Reason: "no running pod found with the matching labels"}
+	}
+	return pods, nil
+}
+
+// getGroupIPs returns the ips of all the pods of the group
+func getGroupIPs(pods []apiv1.Pod) []string {
+	var ips []string
+	for _, pod := range pods {
+		for _, ip := range pod.Status.PodIPs {
+			ips = append(ips, ip.IP)
+		}
+	}
+	return uniqueValues(ips)
+}
+
+// getPodNames returns the names of the pods
+func getPodNames(pods []apiv1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
@@ -150,6 +150,15 @@ func prepareStressChaos(experimentsDetails *experimentTypes.ExperimentDetails, c
 		if err != nil {
 			return stacktrace.Propagate(err, "could not inject chaos")
 		}
+		// verifying that the stress process is consuming the resources inside target container
+		if experimentsDetails.FaultVerification == "true" {
+			if err = verifyChaos(targets[index]); err != nil {
+				if revertErr := terminateProcess(targets[index]); revertErr != nil {
+					return cerrors.PreserveError{ErrString: fmt.Sprintf("[%s,%s]", stacktrace.RootCause(err).Error(), stacktrace.RootCause(revertErr).Error())}
+				}
+				return stacktrace.Propagate(err, "could not verify chaos")
+			}
+		}
 		log.Infof("successfully injected chaos on target: {name: %s, namespace: %v, container: %v}", t.Name, t.Namespace, t.TargetContainer)
 		if err = result.AnnotateChaosResult(resultDetails.Name, chaosDetails.ChaosNamespace, "injected", "pod", t.Name); err != nil {
 			if revertErr := terminateProcess(t); revertErr != nil {
@@ -465,6 +474,7 @@ func getENV(experimentDetails *experimentTypes.ExperimentDetails) {
 	experimentDetails.MemoryConsumption = types.Getenv("MEMORY_CONSUMPTION", "")
 	experimentDetails.VolumeMountPath = types.Getenv("VOLUME_MOUNT_PATH", "")
 	experimentDetails.StressType = types.Getenv("STRESS_TYPE", "")
+	experimentDetails.FaultVerification = types.Getenv("FAULT_VERIFICATION", "true")
 }
 
 // abortWatcher continuously watch for the abort signals
@@ -0,0 +1,178 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containerd/cgroups"
+	cgroupsv2 "github.com/containerd/cgroups/v2"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+// processStat contains the details of the process, parsed from the /proc/<pid>/stat file
+type processStat struct {
+	Pid     int
+	Comm    string
+	Pgrp    int
+	CPUTime uint64
+	RSS     uint64
+}
+
+// verifyChaos verifies that the stress-ng processes are running inside the cgroup of the target container
+// and consuming the cpu or memory resources
+func verifyChaos(t targetDetails) error {
+	log.Infof("[Verification]: Verifying the stress chaos on target: {name: %s, namespace: %v, container: %v}", t.Name, t.Namespace, t.TargetContainer)
+
+	// the stress-ng processes may take a while to start after resuming the paused process
+	var stressors []processStat
+	for retry := 5; retry > 0; retry-- {
+		stressors, err = getStressProcesses(t.Cmd.Process.Pid)
+		if err != nil {
+			return verificationError(t, fmt.Sprintf("unable to list the processes: %s", err.Error()))
+		}
+		if len(stressors) != 0 {
+			break
+		}
+		time.Sleep(1 * time.Second)
+	}
+	if len(stressors) == 0 {
+		return verificationError(t, "no stress-ng process is running")
+	}
+
+	procs, err := getCgroupProcesses(t.CGroupManager)
+	if err != nil {
+		return verificationError(t, fmt.Sprintf("unable to list the processes of the cgroup: %s", err.Error()))
+	}
+	if !containsAnyProcess(procs, stressors) {
+		return verificationError(t, "stress-ng processes are not running inside the cgroup of target container")
+	}
+
+	// sample the resource usage of the stress-ng processes
+	time.Sleep(1 * time.Second)
+	latest, err := getStressProcesses(t.Cmd.Process.Pid)
+	if err != nil {
+		return verificationError(t, fmt.Sprintf("unable to list the processes: %s", err.Error()))
+	}
+	cpuTime, rss := getTotalUsage(stressors)
+	latestCPUTime, latestRSS := getTotalUsage(latest)
+	if latestCPUTime <= cpuTime && latestRSS <= rss {
+		return verificationError(t, "stress-ng processes are not consuming any cpu or memory")
+	}
+
+	log.Infof("[Verification]: The stress chaos is verified on target: {name: %s, namespace: %v, container: %v}, stress-ng processes: %v", t.Name, t.Namespace, t.TargetContainer, len(latest))
+	return nil
+}
+
+// getStressProcesses returns the stress-ng processes of the given process group
+func getStressProcesses(pgrp int) ([]processStat, error) {
+	files, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return nil, err
+	}
+	var stressors []processStat
+	for _, file := range files {
+		stat, err := readProcessStat(file)
+		if err != nil {
+			// the process may have exited in the meantime
+			continue
+		}
+		if stat.Pgrp == pgrp && strings.HasPrefix(stat.Comm, "stress-ng") {
+			stressors = append(stressors, stat)
+		}
+	}
+	return stressors, nil
+}
+
+// readProcessStat parse the /proc/<pid>/stat file
+// the comm is enclosed within the parentheses and may contain spaces, so the fields are parsed after it
+func readProcessStat(path string) (processStat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return processStat{}, err
+	}
+	stat := string(data)
+	start, end := strings.Index(stat, "("), strings.LastIndex(stat, ")")
+	if start < 0 || end < start {
+		return processStat{}, fmt.Errorf("invalid stat format")
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return processStat{}, fmt.Errorf("invalid stat format")
+	}
+
+	var ps processStat
+	if ps.Pid, err = strconv.Atoi(strings.TrimSpace(stat[:start])); err != nil {
+		return processStat{}, err
+	}
+	ps.Comm = stat[start+1 : end]
+	if ps.Pgrp, err = strconv.Atoi(fields[2]); err != nil {
+		return processStat{}, err
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	ps.CPUTime = utime + stime
+	ps.RSS, _ = strconv.ParseUint(fields[21], 10, 64)
+	return ps, nil
+}
+
+// getCgroupProcesses returns the pids of the processes inside the given cgroup
+func getCgroupProcesses(control interface{}) ([]int, error) {
+	var pids []int
+	if cgroups.Mode() == cgroups.Unified {
+		procs, err := control.(*cgroupsv2.Manager).Procs(true)
+		if err != nil {
+			return nil, err
+		}
+		for _, pid := range procs {
+			pids = append(pids, int(pid))
+		}
+		return pids, nil
+	}
+
+	var cgroup1 = control.(cgroups.Cgroup)
+	var errList []string
+	for _, subsystem := range cgroup1.Subsystems() {
+		procs, err := cgroup1.Processes(subsystem.Name(), true)
+		if err != nil {
+			errList = append(errList, err.Error())
+			continue
+		}
+		for _, proc := range procs {
+			pids = append(pids, proc.Pid)
+		}
+		return pids, nil
+	}
+	return nil, fmt.Errorf("[%s]", strings.Join(errList, ","))
+}
+
+// containsAnyProcess checks whether any of the stress-ng processes is present inside the given pids
+func containsAnyProcess(pids []int, stressors []processStat) bool {
+	for _, pid := range pids {
+		for _, stressor := range stressors {
+			if pid == stressor.Pid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getTotalUsage returns the total cpu time (in clock ticks) and rss (in pages) of the given processes
+func getTotalUsage(processes []processStat) (uint64, uint64) {
+	var cpuTime, rss uint64
+	for _, ps := range processes {
+		cpuTime += ps.CPUTime
+		rss += ps.RSS
+	}
+	return cpuTime, rss
+}
+
+// verificationError returns the chaos inject error for the failed verification
+func verificationError(t targetDetails, reason string) error {
+	return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosInject, Source: t.Source, Target: fmt.Sprintf("{podName: %s, namespace: %s, container: %s}", t.Name, t.Namespace, t.TargetContainer), Reason: fmt.Sprintf("fault verification failed: %s", reason)}
+}
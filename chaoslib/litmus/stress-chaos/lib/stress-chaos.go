@@ -323,6 +323,7 @@ func getPodEnv(experimentsDetails *experimentTypes.ExperimentDetails, targets st
 		SetEnv("VOLUME_MOUNT_PATH", experimentsDetails.VolumeMountPath).
 		SetEnv("STRESS_TYPE", experimentsDetails.StressType).
 		SetEnv("INSTANCE_ID", experimentsDetails.InstanceID).
+		SetEnv("FAULT_VERIFICATION", experimentsDetails.FaultVerification).
 		SetEnvFromDownwardAPI("v1", "metadata.name")
 
 	return envDetails.ENV
@@ -1,10 +1,13 @@
 package experiment
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	litmusLIB "github.com/litmuschaos/litmus-go/chaoslib/litmus/pod-network-partition/lib"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
 	clients "github.com/litmuschaos/litmus-go/pkg/clients"
 	"github.com/litmuschaos/litmus-go/pkg/events"
 	experimentEnv "github.com/litmuschaos/litmus-go/pkg/generic/pod-network-partition/environment"
@@ -105,7 +108,21 @@ func PodNetworkPartition(clients clients.ClientSets) {
 	}
 
 	chaosDetails.Phase = types.ChaosInjectPhase
-	if err := litmusLIB.PrepareAndInjectChaos(&experimentsDetails, clients, &resultDetails, &eventsDetails, &chaosDetails); err != nil {
+	switch strings.ToLower(experimentsDetails.PartitionMode) {
+	case "iptables":
+		if err := litmusLIB.PrepareAndInjectIPTablesChaos(&experimentsDetails, clients, &resultDetails, &eventsDetails, &chaosDetails); err != nil {
+			result.RecordAfterFailure(&chaosDetails, &resultDetails, err, clients, &eventsDetails)
+			log.Errorf("Chaos injection failed, err: %v", err)
+			return
+		}
+	case "network-policy":
+		if err := litmusLIB.PrepareAndInjectChaos(&experimentsDetails, clients, &resultDetails, &eventsDetails, &chaosDetails); err != nil {
+			result.RecordAfterFailure(&chaosDetails, &resultDetails, err, clients, &eventsDetails)
+			log.Errorf("Chaos injection failed, err: %v", err)
+			return
+		}
+	default:
+		err := cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("'%s' partition mode is not supported, it should be one of network-policy or iptables", experimentsDetails.PartitionMode)}
 		result.RecordAfterFailure(&chaosDetails, &resultDetails, err, clients, &eventsDetails)
 		log.Errorf("Chaos injection failed, err: %v", err)
 		return
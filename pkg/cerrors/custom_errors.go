@@ -24,6 +24,13 @@ const (
 	ErrorTypeCmdProbe          ErrorType = "CMD_PROBE_ERROR"
 	ErrorTypeHttpProbe         ErrorType = "HTTP_PROBE_ERROR"
 	ErrorTypePromProbe         ErrorType = "PROM_PROBE_ERROR"
+	ErrorTypeWatchProbe        ErrorType = "WATCH_PROBE_ERROR"
+	ErrorTypeLogProbe          ErrorType = "LOG_PROBE_ERROR"
+	ErrorTypeCompositeProbe    ErrorType = "COMPOSITE_PROBE_ERROR"
+	ErrorTypeDBProbe           ErrorType = "DB_PROBE_ERROR"
+	ErrorTypeLibraryProbe      ErrorType = "LIBRARY_PROBE_ERROR"
+	ErrorTypeMetricsProbe      ErrorType = "METRICS_PROBE_ERROR"
+	ErrorTypeKafkaProbe        ErrorType = "KAFKA_PROBE_ERROR"
 )
 
 type userFriendly interface {
@@ -38,6 +38,7 @@ type ExperimentDetails struct {
 	TargetServicePort int
 	Toxicity          int
 	ProxyPort         int
+	FaultVerification string
 
 	Latency            int
 	ResetTimeout       int
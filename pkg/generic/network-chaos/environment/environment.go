@@ -37,6 +37,14 @@ func GetENV(experimentDetails *experimentTypes.ExperimentDetails, expName string
 	experimentDetails.SetHelperData = types.Getenv("SET_HELPER_DATA", "true")
 	experimentDetails.SourcePorts = types.Getenv("SOURCE_PORTS", "")
 	experimentDetails.DestinationPorts = types.Getenv("DESTINATION_PORTS", "")
+	experimentDetails.FaultVerification = types.Getenv("FAULT_VERIFICATION", "true")
+	experimentDetails.VerificationRTTTarget = types.Getenv("VERIFICATION_RTT_TARGET", "")
+	experimentDetails.TrafficDirection = types.Getenv("TRAFFIC_DIRECTION", "egress")
+	experimentDetails.NetworkProtocol = types.Getenv("NETWORK_PROTOCOL", "all")
+	experimentDetails.ExcludedIPs = types.Getenv("EXCLUDED_IPS", "")
+	experimentDetails.ExcludedPorts = types.Getenv("EXCLUDED_PORTS", "")
+	experimentDetails.DestinationRefreshInterval, _ = strconv.Atoi(types.Getenv("DESTINATION_REFRESH_INTERVAL", "0"))
+	experimentDetails.Schedule = types.Getenv("NETWORK_CHAOS_SCHEDULE", "")
 
 	switch expName {
 	case "pod-network-loss":
@@ -55,5 +63,50 @@ func GetENV(experimentDetails *experimentTypes.ExperimentDetails, expName string
 	case "pod-network-duplication":
 		experimentDetails.NetworkPacketDuplicationPercentage = types.Getenv("NETWORK_PACKET_DUPLICATION_PERCENTAGE", "100")
 		experimentDetails.NetworkChaosType = "network-duplication"
+
+	case "pod-network-rate-limit":
+		experimentDetails.NetworkBandwidth = types.Getenv("NETWORK_BANDWIDTH", "1mbit")
+		experimentDetails.Burst = types.Getenv("BURST", "")
+		experimentDetails.Limit = types.Getenv("LIMIT", "")
+		experimentDetails.RateLimitDiscipline = types.Getenv("RATE_LIMIT_DISCIPLINE", "tbf")
+		experimentDetails.NetworkChaosType = "network-rate-limit"
+
+	case "pod-network-reorder":
+		experimentDetails.NetworkPacketReorderPercentage = types.Getenv("NETWORK_PACKET_REORDER_PERCENTAGE", "50")
+		experimentDetails.ReorderCorrelation = types.Getenv("REORDER_CORRELATION", "0")
+		experimentDetails.ReorderGap, _ = strconv.Atoi(types.Getenv("REORDER_GAP", "0"))
+		experimentDetails.NetworkLatency, _ = strconv.Atoi(types.Getenv("NETWORK_LATENCY", "100"))
+		experimentDetails.NetworkChaosType = "network-reorder"
+
+	case "pod-network-degradation":
+		experimentDetails.NetworkLatency, _ = strconv.Atoi(types.Getenv("NETWORK_LATENCY", "0"))
+		experimentDetails.Jitter, _ = strconv.Atoi(types.Getenv("JITTER", "0"))
+		experimentDetails.JitterDistribution = types.Getenv("JITTER_DISTRIBUTION", "")
+		experimentDetails.NetworkPacketLossPercentage = types.Getenv("NETWORK_PACKET_LOSS_PERCENTAGE", "0")
+		experimentDetails.LossCorrelation = types.Getenv("LOSS_CORRELATION", "0")
+		experimentDetails.NetworkPacketDuplicationPercentage = types.Getenv("NETWORK_PACKET_DUPLICATION_PERCENTAGE", "0")
+		experimentDetails.NetworkPacketCorruptionPercentage = types.Getenv("NETWORK_PACKET_CORRUPTION_PERCENTAGE", "0")
+		experimentDetails.NetworkPacketReorderPercentage = types.Getenv("NETWORK_PACKET_REORDER_PERCENTAGE", "0")
+		experimentDetails.ReorderCorrelation = types.Getenv("REORDER_CORRELATION", "0")
+		experimentDetails.ReorderGap, _ = strconv.Atoi(types.Getenv("REORDER_GAP", "0"))
+		experimentDetails.NetworkChaosType = "network-degradation"
+
+	case "node-network-latency":
+		experimentDetails.NetworkLatency, _ = strconv.Atoi(types.Getenv("NETWORK_LATENCY", "2000"))
+		experimentDetails.Jitter, _ = strconv.Atoi(types.Getenv("JITTER", "0"))
+		experimentDetails.NetworkChaosType = "network-latency"
+		setNodeENV(experimentDetails)
+
+	case "node-network-loss":
+		experimentDetails.NetworkPacketLossPercentage = types.Getenv("NETWORK_PACKET_LOSS_PERCENTAGE", "100")
+		experimentDetails.NetworkChaosType = "network-loss"
+		setNodeENV(experimentDetails)
 	}
 }
+
+// setNodeENV fetches the env variables of the node level network chaos
+func setNodeENV(experimentDetails *experimentTypes.ExperimentDetails) {
+	experimentDetails.TargetNodes = types.Getenv("TARGET_NODES", "")
+	experimentDetails.NodesAffectedPerc = types.Getenv("NODES_AFFECTED_PERC", "0")
+	experimentDetails.AllowlistIPs = types.Getenv("ALLOWLIST_IPS", "")
+}
@@ -44,4 +44,58 @@ type ExperimentDetails struct {
 	SetHelperData                      string
 	SourcePorts                        string
 	DestinationPorts                   string
+	FaultVerification                  string
+	VerificationRTTTarget              string
+	NetworkBandwidth                   string
+	Burst                              string
+	Limit                              string
+	RateLimitDiscipline                string
+	Qdisc                              string
+	JitterDistribution                 string
+	LossCorrelation                    string
+	NetworkPacketReorderPercentage     string
+	ReorderCorrelation                 string
+	ReorderGap                         int
+	TrafficDirection                   string
+	NetworkProtocol                    string
+	ExcludedIPs                        string
+	ExcludedPorts                      string
+	DestinationRefreshInterval         int
+	Schedule                           string
+	TargetNodes                        string
+	NodesAffectedPerc                  string
+	AllowlistIPs                       string
+	TargetType                         string
+}
+
+// Schedule contains the details of the schedule, which changes the chaos during the chaos duration
+// it supports ramp, steps and flap schedules
+type Schedule struct {
+	Type  string         `json:"type"`
+	Ramp  *RampSchedule  `json:"ramp,omitempty"`
+	Steps []ScheduleStep `json:"steps,omitempty"`
+	Flap  *FlapSchedule  `json:"flap,omitempty"`
+}
+
+// RampSchedule increases the latency from the initial to the final value over the duration
+type RampSchedule struct {
+	// From and To are the initial and final latency, in ms
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Duration is the duration of the ramp, in seconds, it defaults to the chaos duration
+	Duration int `json:"duration,omitempty"`
+	// Interval is the interval between the latency changes, in seconds
+	Interval int `json:"interval,omitempty"`
+}
+
+// ScheduleStep applies the arguments of the qdisc for the duration, in seconds
+type ScheduleStep struct {
+	Duration int    `json:"duration"`
+	Args     string `json:"args"`
+}
+
+// FlapSchedule alternates the chaos on and off for the given periods, in seconds
+type FlapSchedule struct {
+	OnPeriod  int `json:"onPeriod"`
+	OffPeriod int `json:"offPeriod"`
 }
@@ -16,6 +16,14 @@ const (
 	Error DNSChaosType = "error"
 	// Spoof represents DNS spoofing
 	Spoof DNSChaosType = "spoof"
+	// ServFail represents DNS server failure
+	ServFail DNSChaosType = "servfail"
+	// Refused represents DNS query refusal
+	Refused DNSChaosType = "refused"
+	// Truncate represents truncated DNS responses
+	Truncate DNSChaosType = "truncate"
+	// Latency represents DNS latency
+	Latency DNSChaosType = "latency"
 )
 
 // GetENV fetches all the env variables from the runner pod
@@ -40,12 +48,15 @@ func GetENV(experimentDetails *experimentTypes.ExperimentDetails, expType DNSCha
 	experimentDetails.Sequence = types.Getenv("SEQUENCE", "parallel")
 	experimentDetails.SetHelperData = types.Getenv("SET_HELPER_DATA", "true")
 	experimentDetails.TerminationGracePeriodSeconds, _ = strconv.Atoi(types.Getenv("TERMINATION_GRACE_PERIOD_SECONDS", ""))
+	experimentDetails.MatchScheme = types.Getenv("MATCH_SCHEME", "exact")
+	experimentDetails.RecordTypes = types.Getenv("RECORD_TYPES", "")
+	experimentDetails.FailurePercentage, _ = strconv.Atoi(types.Getenv("FAILURE_PERCENTAGE", "100"))
 	switch expType {
 	case Error:
 		experimentDetails.ExperimentName = types.Getenv("EXPERIMENT_NAME", "pod-dns-error")
 		experimentDetails.TargetHostNames = types.Getenv("TARGET_HOSTNAMES", "")
-		experimentDetails.MatchScheme = types.Getenv("MATCH_SCHEME", "exact")
 		experimentDetails.ChaosType = types.Getenv("CHAOS_TYPE", "error")
+		experimentDetails.DNSLatency, _ = strconv.Atoi(types.Getenv("DNS_LATENCY", "0"))
 	case Spoof:
 		experimentDetails.ExperimentName = types.Getenv("EXPERIMENT_NAME", "pod-dns-spoof")
 		experimentDetails.SpoofMap = types.Getenv("SPOOF_MAP", "")
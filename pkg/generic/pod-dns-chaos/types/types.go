@@ -29,6 +29,9 @@ type ExperimentDetails struct {
 	SpoofMap                      string
 	MatchScheme                   string
 	ChaosType                     string
+	RecordTypes                   string
+	FailurePercentage             int
+	DNSLatency                    int
 	ContainerRuntime              string
 	ChaosServiceAccount           string
 	Sequence                      string
@@ -32,6 +32,17 @@ func GetENV(experimentDetails *experimentTypes.ExperimentDetails) {
 	experimentDetails.PodSelector = types.Getenv("POD_SELECTOR", "")
 	experimentDetails.NamespaceSelector = types.Getenv("NAMESPACE_SELECTOR", "")
 	experimentDetails.PORTS = types.Getenv("PORTS", "")
+	experimentDetails.PartitionMode = types.Getenv("PARTITION_MODE", "network-policy")
+	experimentDetails.Action = types.Getenv("ACTION", "DROP")
+	experimentDetails.ExemptNodeIP = types.Getenv("EXEMPT_NODE_IP", "true")
+	experimentDetails.LIBImage = types.Getenv("LIB_IMAGE", "litmuschaos/go-runner:latest")
+	experimentDetails.ContainerRuntime = types.Getenv("CONTAINER_RUNTIME", "containerd")
+	experimentDetails.SocketPath = types.Getenv("SOCKET_PATH", "/run/containerd/containerd.sock")
+	experimentDetails.ChaosServiceAccount = types.Getenv("CHAOS_SERVICE_ACCOUNT", "")
+	experimentDetails.TerminationGracePeriodSeconds, _ = strconv.Atoi(types.Getenv("TERMINATION_GRACE_PERIOD_SECONDS", ""))
+	experimentDetails.SetHelperData = types.Getenv("SET_HELPER_DATA", "true")
+	experimentDetails.GroupALabels = types.Getenv("GROUP_A_LABELS", "")
+	experimentDetails.GroupBLabels = types.Getenv("GROUP_B_LABELS", "")
 
 	experimentDetails.AppNS, experimentDetails.AppKind, experimentDetails.AppLabel = getAppDetails()
 }
@@ -6,25 +6,38 @@ import (
 
 // ExperimentDetails is for collecting all the experiment-related details
 type ExperimentDetails struct {
-	ExperimentName     string
-	EngineName         string
-	ChaosDuration      int
-	RampTime           int
-	AppNS              string
-	AppLabel           string
-	AppKind            string
-	ChaosUID           clientTypes.UID
-	InstanceID         string
-	LIBImagePullPolicy string
-	ChaosNamespace     string
-	ChaosPodName       string
-	Timeout            int
-	Delay              int
-	TargetContainer    string
-	DestinationHosts   string
-	DestinationIPs     string
-	PolicyTypes        string
-	PodSelector        string
-	NamespaceSelector  string
-	PORTS              string
+	ExperimentName                string
+	EngineName                    string
+	ChaosDuration                 int
+	RampTime                      int
+	AppNS                         string
+	AppLabel                      string
+	AppKind                       string
+	ChaosUID                      clientTypes.UID
+	InstanceID                    string
+	LIBImagePullPolicy            string
+	ChaosNamespace                string
+	ChaosPodName                  string
+	Timeout                       int
+	Delay                         int
+	TargetContainer               string
+	DestinationHosts              string
+	DestinationIPs                string
+	PolicyTypes                   string
+	PodSelector                   string
+	NamespaceSelector             string
+	PORTS                         string
+	PartitionMode                 string
+	Action                        string
+	LIBImage                      string
+	ContainerRuntime              string
+	SocketPath                    string
+	ChaosServiceAccount           string
+	TerminationGracePeriodSeconds int
+	SetHelperData                 string
+	PeerIPs                       string
+	PartitionPorts                string
+	ExemptNodeIP                  string
+	GroupALabels                  string
+	GroupBLabels                  string
 }
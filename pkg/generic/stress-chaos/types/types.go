@@ -41,4 +41,5 @@ type ExperimentDetails struct {
 	IsTargetContainerProvided       bool
 	NodeLabel                       string
 	SetHelperData                   string
+	FaultVerification               string
 }
@@ -0,0 +1,153 @@
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// probeOutput contains the details of a named output, which is extracted from the probe response
+// the response is the command output for cmd probe, response body for http probe and metrics value for prom probe
+type probeOutput struct {
+	// Name of the output, it can be referenced as {{ .<probeName>.ProbeArtifacts.Outputs.<name> }}
+	Name string `json:"name"`
+	// JSONPath extracts the output from the json response
+	JSONPath string `json:"jsonPath,omitempty"`
+	// Regex extracts the first capture group (or the entire match, if there is no capture group) from the response
+	Regex string `json:"regex,omitempty"`
+}
+
+// getProbeOutputs parse the named outputs from the data field of the probe
+// these are provided under the outputs key, in yaml/json format
+func getProbeOutputs(probe v1alpha1.ProbeAttributes) []probeOutput {
+	data := struct {
+		Outputs []probeOutput `json:"outputs,omitempty"`
+	}{}
+	if probe.Data == "" {
+		return nil
+	}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &data); err != nil {
+		return nil
+	}
+	return data.Outputs
+}
+
+// registerProbeArtifacts stores the probe response and the named outputs extracted from it inside the probe artifacts
+// the stored artifacts can be referenced by the templated fields of the subsequent probes
+func registerProbeArtifacts(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, response string, errorCode cerrors.ErrorType) error {
+	probes := types.ProbeArtifact{}
+	probes.ProbeArtifacts.Register = response
+
+	outputs := getProbeOutputs(probe)
+	if len(outputs) != 0 {
+		probes.ProbeArtifacts.Outputs = map[string]string{}
+	}
+	for _, output := range outputs {
+		value, err := extractOutput(output, response)
+		if err != nil {
+			return cerrors.Error{ErrorCode: errorCode, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to extract the '%s' output, err: %v", output.Name, err)}
+		}
+		probes.ProbeArtifacts.Outputs[output.Name] = value
+	}
+	ResultLock.Lock()
+	resultDetails.ProbeArtifacts[probe.Name] = probes
+	ResultLock.Unlock()
+	return nil
+}
+
+// registerDefaultOutputs stores the given outputs inside the probe artifacts, for the probes which report multiple values in a single response
+// the named outputs of the probe take precedence over these outputs
+func registerDefaultOutputs(probeName string, resultDetails *types.ResultDetails, outputs map[string]string) {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+	probes := resultDetails.ProbeArtifacts[probeName]
+	if probes.ProbeArtifacts.Outputs == nil {
+		probes.ProbeArtifacts.Outputs = map[string]string{}
+	}
+	for name, value := range outputs {
+		if _, ok := probes.ProbeArtifacts.Outputs[name]; !ok {
+			probes.ProbeArtifacts.Outputs[name] = value
+		}
+	}
+	resultDetails.ProbeArtifacts[probeName] = probes
+}
+
+// extractOutput extracts the output from the response using jsonPath or regex
+// it returns the entire response, if none of them is provided
+func extractOutput(output probeOutput, response string) (string, error) {
+	switch {
+	case output.JSONPath != "":
+		return jsonPathValue(output.JSONPath, response)
+	case output.Regex != "":
+		return regexCapture(output.Regex, response)
+	default:
+		return response, nil
+	}
+}
+
+// jsonPathValue returns the value of the given jsonpath from the json input
+func jsonPathValue(path, input string) (string, error) {
+	var obj interface{}
+	if err := json.Unmarshal([]byte(input), &obj); err != nil {
+		return "", fmt.Errorf("input is not a valid json, err: %v", err)
+	}
+	return extractFieldValue(obj, path)
+}
+
+// regexCapture returns the first capture group of the given regex from the input
+// it returns the entire match, if regex doesn't contain any capture group
+func regexCapture(pattern, input string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a valid regex, err: %v", pattern, err)
+	}
+	match := re.FindStringSubmatch(input)
+	switch {
+	case match == nil:
+		return "", fmt.Errorf("no match found for '%s' regex", pattern)
+	case len(match) > 1:
+		return match[1], nil
+	default:
+		return match[0], nil
+	}
+}
+
+// templateFuncs contains the helper functions, which can be used inside the templated fields of the probes
+// the input is passed as the last argument, so that the functions can be used inside the pipelines
+// e.g. {{ .probe1.ProbeArtifacts.Register | jsonpath "{.items[0].id}" | default "none" }}
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"jsonpath":     jsonPathValue,
+		"regexCapture": regexCapture,
+		"split": func(sep, input string) []string {
+			return strings.Split(input, sep)
+		},
+		"trim": strings.TrimSpace,
+		"default": func(defaultValue, value string) string {
+			if strings.TrimSpace(value) == "" {
+				return defaultValue
+			}
+			return value
+		},
+		"env": os.Getenv,
+		"now": func(layout string) string {
+			if layout == "" {
+				layout = time.RFC3339
+			}
+			return time.Now().Format(layout)
+		},
+		"unixTime": func() string {
+			return strconv.FormatInt(time.Now().Unix(), 10)
+		},
+	}
+}
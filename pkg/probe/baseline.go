@@ -0,0 +1,193 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	cmp "github.com/litmuschaos/litmus-go/pkg/probe/comparator"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	litmusexec "github.com/litmuschaos/litmus-go/pkg/utils/exec"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// defaultBaselineKey is the baseline key of the probes, which compare a single value
+const defaultBaselineKey = "value"
+
+// compareWithBaseline records the value of the first evaluation of the probe as baseline
+// and compares the values of the subsequent evaluations relative to the baseline
+// the baselines are recorded per key, so that the fields or resources compared by the same probe use their own baseline
+func compareWithBaseline(compare *cmp.Model, comparator v1alpha1.ComparatorInfo, probeName, baselineKey, value string, resultDetails *types.ResultDetails, errorCode cerrors.ErrorType) (string, error) {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+	var probeDetails *types.ProbeDetails
+	if resultDetails != nil {
+		probeDetails = getProbeByName(probeName, resultDetails.ProbeDetails)
+	}
+	if probeDetails == nil {
+		return "", cerrors.Error{ErrorCode: errorCode, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("criteria '%s' is not supported in the probe", comparator.Criteria)}
+	}
+
+	baseline, recorded := probeDetails.Baselines[baselineKey]
+	if !recorded {
+		// the eot and onchaos probes record the baseline only in the prechaos phase
+		switch strings.ToLower(probeDetails.Mode) {
+		case "eot", "onchaos":
+			if !probeDetails.RecordBaseline {
+				return "", cerrors.Error{ErrorCode: errorCode, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("baseline value of '%s' is not recorded in the prechaos phase", baselineKey)}
+			}
+		}
+		if probeDetails.Baselines == nil {
+			probeDetails.Baselines = map[string]string{}
+		}
+		probeDetails.Baselines[baselineKey] = value
+		log.Infof("[Probe]: The baseline value of '%v' for %v probe is recorded as '%v'", baselineKey, probeName, value)
+		return fmt.Sprintf("Baseline value '%s' is recorded for the probe", value), nil
+	}
+
+	if err := compare.Baseline(baseline).CompareBaseline(errorCode); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Probe responded with a valid output. Actual value '%s' follows the '%s %s' criteria relative to the Baseline value '%s'", value, comparator.Criteria, comparator.Value, baseline), nil
+}
+
+// getProbeComparators returns the comparators of the probe, which can compare the value relative to the baseline
+// the comparators, which can't be parsed, are skipped here and reported in the probe evaluation
+func getProbeComparators(probe v1alpha1.ProbeAttributes, chaosDetails *types.ChaosDetails) []v1alpha1.ComparatorInfo {
+	var comparators []v1alpha1.ComparatorInfo
+	switch strings.ToLower(probe.Type) {
+	case "cmdprobe":
+		cmdComparators := getCmdProbeComparators(probe)
+		for _, comparator := range []*v1alpha1.ComparatorInfo{cmdComparators.Stdout, cmdComparators.Stderr, cmdComparators.ExitCode} {
+			if comparator != nil {
+				comparators = append(comparators, *comparator)
+			}
+		}
+	case "promprobe":
+		comparators = append(comparators, probe.PromProbeInputs.Comparator)
+	case "httpprobe":
+		if comparator := getBodyComparator(probe); comparator != nil {
+			comparators = append(comparators, *comparator)
+		}
+	case "k8sprobe":
+		inputs := k8sCompareInputs{}
+		if strings.ToLower(probe.K8sProbeInputs.Operation) == "compare" && sigsyaml.Unmarshal([]byte(probe.Data), &inputs) == nil {
+			comparators = append(comparators, inputs.Comparator)
+		}
+	case "dbprobe":
+		if inputs, err := getDBProbeInputs(probe); err == nil && inputs.Comparator != nil {
+			comparators = append(comparators, *inputs.Comparator)
+		}
+	case "metricsprobe":
+		if inputs, err := getMetricsProbeInputs(probe, chaosDetails); err == nil {
+			comparators = append(comparators, inputs.Comparator)
+		}
+	case "kafkaprobe":
+		if inputs, err := getKafkaProbeInputs(probe); err == nil && inputs.ConsumerLag != nil {
+			comparators = append(comparators, inputs.ConsumerLag.Comparator)
+		}
+	case "logprobe":
+		if inputs, _, err := getLogProbeInputs(probe); err == nil {
+			comparators = append(comparators, inputs.Comparator)
+		}
+	}
+	return comparators
+}
+
+// hasBaselineCriteria returns true, if any comparator of the probe compares the value relative to the baseline
+func hasBaselineCriteria(probe v1alpha1.ProbeAttributes, chaosDetails *types.ChaosDetails) bool {
+	for _, comparator := range getProbeComparators(probe, chaosDetails) {
+		if cmp.IsBaselineCriteria(comparator.Criteria) {
+			return true
+		}
+	}
+	return false
+}
+
+// captureBaseline records the pre-chaos baseline value for the eot and onchaos probes,
+// which compare the value relative to the baseline. these probes are not evaluated in the prechaos phase otherwise
+func captureBaseline(probe v1alpha1.ProbeAttributes, chaosDetails *types.ChaosDetails, clients clients.ClientSets, resultDetails *types.ResultDetails) {
+	if !hasBaselineCriteria(probe, chaosDetails) {
+		return
+	}
+
+	if !setRecordBaseline(resultDetails, probe.Name, true) {
+		return
+	}
+	defer setRecordBaseline(resultDetails, probe.Name, false)
+
+	log.Infof("[Probe]: Recording the baseline value for %v probe", probe.Name)
+	ctx := context.Background()
+	var err error
+	switch strings.ToLower(probe.Type) {
+	case "cmdprobe":
+		if reflect.DeepEqual(probe.CmdProbeInputs.Source, v1alpha1.SourceDetails{}) {
+			err = triggerInlineCmdProbe(ctx, probe, resultDetails)
+			break
+		}
+		var execCommandDetails litmusexec.PodDetails
+		if execCommandDetails, err = createHelperPod(probe, resultDetails, clients, chaosDetails); err != nil {
+			break
+		}
+		err = triggerSourceCmdProbe(ctx, probe, execCommandDetails, clients, resultDetails)
+		if deleteErr := deleteProbePod(chaosDetails, clients, getRunIDFromProbe(resultDetails, probe.Name, probe.Type), probe.Name); deleteErr != nil {
+			log.Errorf("unable to delete the probe pod, err: %v", deleteErr)
+		}
+	case "promprobe":
+		err = triggerPromProbe(ctx, probe, resultDetails)
+	case "httpprobe":
+		err = triggerHTTPProbe(ctx, probe, resultDetails)
+	case "k8sprobe":
+		err = triggerK8sProbe(ctx, probe, clients, resultDetails)
+	case "dbprobe":
+		err = triggerDBProbe(ctx, probe, clients, resultDetails, chaosDetails)
+	case "metricsprobe":
+		err = triggerMetricsProbe(ctx, probe, clients, resultDetails, chaosDetails)
+	case "kafkaprobe":
+		err = triggerKafkaProbe(ctx, probe, clients, resultDetails, chaosDetails)
+	case "logprobe":
+		// the eot log probe streams the logs for the probe timeout window, so the baseline is counted over the same window
+		err = triggerLogProbe(ctx, probe, clients, resultDetails, time.Duration(probe.RunProperties.ProbeTimeout)*time.Millisecond)
+	}
+	// the probe fails in the later evaluations, if the baseline value is not recorded
+	if err != nil {
+		log.Errorf("unable to record the baseline value for %v probe, err: %v", probe.Name, err)
+	}
+}
+
+// setRecordBaseline marks whether the baseline value of the probe can be recorded
+// it returns false, if the probe is not found
+func setRecordBaseline(resultDetails *types.ResultDetails, probeName string, record bool) bool {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+	probeDetails := getProbeByName(probeName, resultDetails.ProbeDetails)
+	if probeDetails == nil {
+		return false
+	}
+	probeDetails.RecordBaseline = record
+	return true
+}
+
+// GetProbeBaselines returns the recorded baseline values of the probes, keyed by the probe name and the baseline key
+func GetProbeBaselines(resultDetails *types.ResultDetails) map[string]map[string]string {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+	baselines := map[string]map[string]string{}
+	for _, probe := range resultDetails.ProbeDetails {
+		if len(probe.Baselines) == 0 {
+			continue
+		}
+		values := map[string]string{}
+		for key, value := range probe.Baselines {
+			values[key] = value
+		}
+		baselines[probe.Name] = values
+	}
+	return baselines
+}
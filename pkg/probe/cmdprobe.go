@@ -5,16 +5,19 @@ import (
 	"context"
 	"fmt"
 	"github.com/litmuschaos/litmus-go/pkg/utils/stringutils"
+	"math"
 	"os/exec"
 	"reflect"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	"github.com/litmuschaos/litmus-go/pkg/cerrors"
 	"github.com/litmuschaos/litmus-go/pkg/clients"
 	"github.com/litmuschaos/litmus-go/pkg/log"
-	"github.com/litmuschaos/litmus-go/pkg/math"
 	cmp "github.com/litmuschaos/litmus-go/pkg/probe/comparator"
 	"github.com/litmuschaos/litmus-go/pkg/status"
 	"github.com/litmuschaos/litmus-go/pkg/types"
@@ -24,8 +27,28 @@ import (
 	"github.com/sirupsen/logrus"
 	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
+// maxOutputLength is the maximum length of the command output, included inside the probe description
+const maxOutputLength = 256
+
+// cmdProbeOutput contains the stdout, stderr and exit code of the cmd probe command
+type cmdProbeOutput struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// cmdProbeComparators contains the comparators for the output streams and exit code of the command
+// the stderr and exit code comparators are provided under the stderrComparator and exitCodeComparator keys
+// of the data field, in yaml/json format. The stdout comparator is provided inside the cmd probe inputs
+type cmdProbeComparators struct {
+	Stdout   *v1alpha1.ComparatorInfo `json:"-"`
+	Stderr   *v1alpha1.ComparatorInfo `json:"stderrComparator,omitempty"`
+	ExitCode *v1alpha1.ComparatorInfo `json:"exitCodeComparator,omitempty"`
+}
+
 // prepareCmdProbe contains the steps to prepare the cmd probe
 // cmd probe can be used to add the command probes
 // it can be of two types one: which need a source(an external image)
@@ -52,7 +75,8 @@ func prepareCmdProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets,
 }
 
 // triggerInlineCmdProbe trigger the cmd probe and storing the output into the out buffer
-func triggerInlineCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) error {
+func triggerInlineCmdProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) error {
+	var err error
 	var description string
 
 	// It parses the templated command and return normal string
@@ -67,35 +91,23 @@ func triggerInlineCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.
 	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
 	// for a timeout, it will run the command, if it fails wait for the interval and again execute the command until timeout expires
 	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
 		Timeout(int64(probe.RunProperties.ProbeTimeout)).
 		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
 		TryWithTimeout(func(attempt uint) error {
-			var out, stdErr bytes.Buffer
-			// run the inline command probe
-			cmd := exec.Command("/bin/sh", "-c", probe.CmdProbeInputs.Command)
-			cmd.Stdout = &out
-			cmd.Stderr = &stdErr
-			if err := cmd.Run(); err != nil {
-				return cerrors.Error{ErrorCode: cerrors.ErrorTypeCmdProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to run command: %s", out.String())}
+			// run the inline command probe, it is killed if it doesn't complete within the probe timeout
+			output, err := runInlineCommand(ctx, probe.CmdProbeInputs.Command, time.Duration(probe.RunProperties.ProbeTimeout)*time.Millisecond)
+			if err != nil {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeCmdProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to run command, err: %v", err)}
 			}
 
 			rc := getAndIncrementRunCount(resultDetails, probe.Name)
-			description, err = validateResult(probe.CmdProbeInputs.Comparator, probe.Name, strings.TrimSpace(out.String()), rc)
-			if err != nil {
-				if strings.TrimSpace(stdErr.String()) != "" {
-					return cerrors.Error{
-						ErrorCode: cerrors.ErrorTypeCmdProbe,
-						Target:    probe.Name,
-						Reason:    stdErr.String(),
-					}
-				}
+			if description, err = validateCmdOutput(probe, output, rc, resultDetails); err != nil {
 				return err
 			}
 
-			probes := types.ProbeArtifact{}
-			probes.ProbeArtifacts.Register = strings.TrimSpace(out.String())
-			resultDetails.ProbeArtifacts[probe.Name] = probes
-			return nil
+			// storing the output and the named outputs inside the probe artifacts
+			return registerProbeArtifacts(probe, resultDetails, output.Stdout, cerrors.ErrorTypeCmdProbe)
 		}); err != nil {
 		return err
 	}
@@ -105,7 +117,8 @@ func triggerInlineCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.
 }
 
 // triggerSourceCmdProbe trigger the cmd probe inside the external pod
-func triggerSourceCmdProbe(probe v1alpha1.ProbeAttributes, execCommandDetails litmusexec.PodDetails, clients clients.ClientSets, resultDetails *types.ResultDetails) error {
+func triggerSourceCmdProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, execCommandDetails litmusexec.PodDetails, clients clients.ClientSets, resultDetails *types.ResultDetails) error {
+	var err error
 	var description string
 
 	// It parses the templated command and return normal string
@@ -120,32 +133,25 @@ func triggerSourceCmdProbe(probe v1alpha1.ProbeAttributes, execCommandDetails li
 	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
 	// for a timeout, it will run the command, if it fails wait for the interval and again execute the command until timeout expires
 	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
 		Timeout(int64(probe.RunProperties.ProbeTimeout)).
 		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
 		TryWithTimeout(func(attempt uint) error {
-			command := append([]string{"/bin/sh", "-c"}, probe.CmdProbeInputs.Command)
+			timeout := time.Duration(probe.RunProperties.ProbeTimeout) * time.Millisecond
 			// exec inside the external pod to get the o/p of given command
-			output, stdErr, err := litmusexec.Exec(&execCommandDetails, clients, command)
+			stdout, stderr, exitCode, err := litmusexec.ExecWithTimeout(&execCommandDetails, clients, getSourceCommand(probe.CmdProbeInputs.Command, timeout), timeout)
 			if err != nil {
 				return stacktrace.Propagate(err, "unable to get output of cmd command")
 			}
+			output := cmdProbeOutput{Stdout: strings.TrimSpace(stdout), Stderr: strings.TrimSpace(stderr), ExitCode: exitCode}
 
 			rc := getAndIncrementRunCount(resultDetails, probe.Name)
-			if description, err = validateResult(probe.CmdProbeInputs.Comparator, probe.Name, strings.TrimSpace(output), rc); err != nil {
-				if strings.TrimSpace(stdErr) != "" {
-					return cerrors.Error{
-						ErrorCode: cerrors.ErrorTypeCmdProbe,
-						Target:    probe.Name,
-						Reason:    stdErr,
-					}
-				}
+			if description, err = validateCmdOutput(probe, output, rc, resultDetails); err != nil {
 				return err
 			}
 
-			probes := types.ProbeArtifact{}
-			probes.ProbeArtifacts.Register = strings.TrimSpace(output)
-			resultDetails.ProbeArtifacts[probe.Name] = probes
-			return nil
+			// storing the output and the named outputs inside the probe artifacts
+			return registerProbeArtifacts(probe, resultDetails, output.Stdout, cerrors.ErrorTypeCmdProbe)
 		}); err != nil {
 		return err
 	}
@@ -154,6 +160,145 @@ func triggerSourceCmdProbe(probe v1alpha1.ProbeAttributes, execCommandDetails li
 	return nil
 }
 
+// runInlineCommand runs the command inside the experiment pod and returns the stdout, stderr and exit code of the command
+// the command runs in a dedicated process group, the whole group is killed if it doesn't complete within the timeout or the context is cancelled
+func runInlineCommand(ctx context.Context, command string, timeout time.Duration) (cmdProbeOutput, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return cmdProbeOutput{}, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case err := <-done:
+		output := cmdProbeOutput{Stdout: strings.TrimSpace(stdout.String()), Stderr: strings.TrimSpace(stderr.String())}
+		if err != nil {
+			exitErr, ok := err.(*exec.ExitError)
+			if !ok {
+				return cmdProbeOutput{}, err
+			}
+			output.ExitCode = exitErr.ExitCode()
+		}
+		return output, nil
+	case <-timer:
+		// killing the child processes as well, otherwise they keep holding the output pipes
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+			log.Errorf("unable to kill the command, err: %v", err)
+		}
+		<-done
+		return cmdProbeOutput{}, fmt.Errorf("command is killed as it did not complete within %v", timeout)
+	case <-ctx.Done():
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+			log.Errorf("unable to kill the command, err: %v", err)
+		}
+		<-done
+		return cmdProbeOutput{}, fmt.Errorf("command is killed as the probe is stopped")
+	}
+}
+
+// getSourceCommand returns the command to run inside the external pod
+// the exec is stopped by closing its connection once the probe timeout expires. Additionally the command is wrapped
+// inside the timeout utility, if it is available inside the source image, so that it is killed inside the pod as well
+func getSourceCommand(command string, timeout time.Duration) []string {
+	if timeout <= 0 {
+		return []string{"/bin/sh", "-c", command}
+	}
+	seconds := int64(math.Ceil(timeout.Seconds()))
+	script := `if command -v timeout >/dev/null 2>&1; then exec timeout -s KILL "$1" /bin/sh -c "$2"; fi; exec /bin/sh -c "$2"`
+	return []string{"/bin/sh", "-c", script, "sh", strconv.FormatInt(seconds, 10), command}
+}
+
+// validateCmdOutput validates the stdout, stderr and exit code of the command against the corresponding comparators
+// the non-zero exit code fails the probe, unless the exit code comparator is provided
+func validateCmdOutput(probe v1alpha1.ProbeAttributes, output cmdProbeOutput, rc int, resultDetails *types.ResultDetails) (string, error) {
+	comparators := getCmdProbeComparators(probe)
+
+	if comparators.ExitCode == nil && output.ExitCode != 0 {
+		return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeCmdProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("command exited with %d exit code, stderr: '%s'", output.ExitCode, truncateOutput(output.Stderr))}
+	}
+
+	targets := []struct {
+		name       string
+		comparator *v1alpha1.ComparatorInfo
+		value      string
+	}{
+		{name: "stdout", comparator: comparators.Stdout, value: output.Stdout},
+		{name: "stderr", comparator: comparators.Stderr, value: output.Stderr},
+		{name: "exitCode", comparator: comparators.ExitCode, value: strconv.Itoa(output.ExitCode)},
+	}
+
+	var descriptions []string
+	for _, target := range targets {
+		if target.comparator == nil {
+			continue
+		}
+		description, err := validateResult(*target.comparator, probe.Name, target.name, target.value, rc, resultDetails, cerrors.ErrorTypeCmdProbe)
+		if err != nil {
+			reason := truncateValue(getDescription(err), target.value)
+			if target.name != "stderr" && output.Stderr != "" {
+				reason = fmt.Sprintf("%s, stderr: '%s'", reason, truncateOutput(output.Stderr))
+			}
+			return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeCmdProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("%s comparison failed: %s", target.name, reason)}
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s: %s", target.name, truncateValue(description, target.value)))
+	}
+	if len(descriptions) == 0 {
+		return "Probe command completed successfully", nil
+	}
+	return strings.Join(descriptions, "; "), nil
+}
+
+// getCmdProbeComparators returns the comparators for the stdout, stderr and exit code of the command
+// the stdout comparator is skipped if it is not provided
+func getCmdProbeComparators(probe v1alpha1.ProbeAttributes) cmdProbeComparators {
+	var comparators cmdProbeComparators
+	if probe.Data != "" {
+		// the data field is shared with the other probe inputs, so the parse errors are ignored
+		_ = sigsyaml.Unmarshal([]byte(probe.Data), &comparators)
+	}
+	if probe.CmdProbeInputs.Comparator.Type != "" || probe.CmdProbeInputs.Comparator.Criteria != "" {
+		comparators.Stdout = &probe.CmdProbeInputs.Comparator
+	}
+	return comparators
+}
+
+// truncateValue truncates the occurrences of the given value inside the message
+func truncateValue(message, value string) string {
+	if len(value) <= maxOutputLength {
+		return message
+	}
+	return strings.ReplaceAll(message, value, truncateOutput(value))
+}
+
+// truncateOutput truncates the output, which exceeds the max length
+// so that the large outputs don't bloat the probe description
+func truncateOutput(output string) string {
+	if len(output) <= maxOutputLength {
+		return output
+	}
+	end := maxOutputLength
+	// not splitting the multi-byte characters
+	for end > 0 && !utf8.RuneStart(output[end]) {
+		end--
+	}
+	return fmt.Sprintf("%s...(truncated %d bytes)", output[:end], len(output)-end)
+}
+
 // createProbePod creates an external pod with source image for the cmd probe
 func createProbePod(clients clients.ClientSets, chaosDetails *types.ChaosDetails, runID string, source v1alpha1.SourceDetails, probeName string) error {
 	//deriving serviceAccount name for the probe pod
@@ -317,216 +462,54 @@ func deleteProbePod(chaosDetails *types.ChaosDetails, clients clients.ClientSets
 		})
 }
 
-// triggerInlineContinuousCmdProbe trigger the inline continuous cmd probes
-func triggerInlineContinuousCmdProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-	var isExperimentFailed bool
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-	}
-
-	// it trigger the inline cmd probe for the entire duration of chaos and it fails, if any err encounter
-	// it marked the error for the probes, if any
-loop:
-	for {
-		err := triggerInlineCmdProbe(probe, chaosresult)
-		// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-		if err != nil {
-			err = addProbePhase(err, string(chaosDetails.Phase))
-			for index := range chaosresult.ProbeDetails {
-				if chaosresult.ProbeDetails[index].Name == probe.Name {
-					chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-					chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-					log.Errorf("The %v cmd probe has been Failed, err: %v", probe.Name, err)
-					isExperimentFailed = true
-					break loop
-				}
-			}
-		}
-		// waiting for the probe polling interval
-		time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
-}
-
-// triggerInlineOnChaosCmdProbe trigger the inline onchaos cmd probes
-func triggerInlineOnChaosCmdProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-	var isExperimentFailed bool
-	duration := chaosDetails.ChaosDuration
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-		duration = math.Maximum(0, duration-probe.RunProperties.InitialDelaySeconds)
-	}
-
-	var endTime <-chan time.Time
-	timeDelay := time.Duration(duration) * time.Second
-
-	// it trigger the inline cmd probe for the entire duration of chaos and it fails, if any err encounter
-	// it marked the error for the probes, if any
-loop:
-	for {
-		endTime = time.After(timeDelay)
-		select {
-		case <-endTime:
-			log.Infof("[Chaos]: Time is up for the %v probe", probe.Name)
-			endTime = nil
-			break loop
-		default:
-			// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-			if err = triggerInlineCmdProbe(probe, chaosresult); err != nil {
-				err = addProbePhase(err, string(chaosDetails.Phase))
-				for index := range chaosresult.ProbeDetails {
-					if chaosresult.ProbeDetails[index].Name == probe.Name {
-						chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-						chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-						log.Errorf("The %v cmd probe has been Failed, err: %v", probe.Name, err)
-						isExperimentFailed = true
-						break loop
-					}
-				}
-			}
-			// waiting for the probe polling interval
-			time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-		}
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
-}
-
-// triggerSourceOnChaosCmdProbe trigger the onchaos cmd probes having need some external source image
-func triggerSourceOnChaosCmdProbe(probe v1alpha1.ProbeAttributes, execCommandDetails litmusexec.PodDetails, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-
-	var isExperimentFailed bool
-	duration := chaosDetails.ChaosDuration
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-		duration = math.Maximum(0, duration-probe.RunProperties.InitialDelaySeconds)
-	}
-
-	endTime := time.After(time.Duration(duration) * time.Second)
-
-	// it trigger the cmd probe for the entire duration of chaos and it fails, if any err encounter
-	// it marked the error for the probes, if any
-loop:
-	for {
-		select {
-		case <-endTime:
-			log.Infof("[Chaos]: Time is up for the %v probe", probe.Name)
-			endTime = nil
-			break loop
-		default:
-			// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-			if err = triggerSourceCmdProbe(probe, execCommandDetails, clients, chaosresult); err != nil {
-				err = addProbePhase(err, string(chaosDetails.Phase))
-				for index := range chaosresult.ProbeDetails {
-					if chaosresult.ProbeDetails[index].Name == probe.Name {
-						chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-						chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-						log.Errorf("The %v cmd probe has been Failed, err: %v", probe.Name, err)
-						isExperimentFailed = true
-						break loop
-					}
-				}
-			}
-			// waiting for the probe polling interval
-			time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-		}
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-
-	}
-}
-
-// triggerSourceContinuousCmdProbe trigger the continuous cmd probes having need some external source image
-func triggerSourceContinuousCmdProbe(probe v1alpha1.ProbeAttributes, execCommandDetails litmusexec.PodDetails, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-
-	var isExperimentFailed bool
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-	}
-
-	// it trigger the cmd probe for the entire duration of chaos and it fails, if any err encounter
-	// it marked the error for the probes, if any
-loop:
-	for {
-		err = triggerSourceCmdProbe(probe, execCommandDetails, clients, chaosresult)
-		// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-		if err != nil {
-			err = addProbePhase(err, string(chaosDetails.Phase))
-			for index := range chaosresult.ProbeDetails {
-				if chaosresult.ProbeDetails[index].Name == probe.Name {
-					chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-					chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-					log.Errorf("The %v cmd probe has been Failed, err: %v", probe.Name, err)
-					isExperimentFailed = true
-					break loop
-				}
-			}
-		}
-		// waiting for the probe polling interval
-		time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
-}
-
 // validateResult validate the probe result to specified comparison operation
 // it supports int, float, string operands
-func validateResult(comparator v1alpha1.ComparatorInfo, probeName, cmdOutput string, rc int) (string, error) {
+// the baseline key identifies the baseline value of the compared field, if the probe compares multiple fields or resources
+func validateResult(comparator v1alpha1.ComparatorInfo, probeName, baselineKey, cmdOutput string, rc int, resultDetails *types.ResultDetails, errorCode cerrors.ErrorType) (string, error) {
 
+	var err error
 	compare := cmp.RunCount(rc).
 		FirstValue(cmdOutput).
 		SecondValue(comparator.Value).
 		Criteria(comparator.Criteria).
 		ProbeName(probeName)
 
+	// comparing the output relative to the baseline value, recorded in the first evaluation of the probe
+	if cmp.IsBaselineCriteria(comparator.Criteria) {
+		return compareWithBaseline(compare, comparator, probeName, baselineKey, cmdOutput, resultDetails, errorCode)
+	}
+
 	switch strings.ToLower(comparator.Type) {
 	case "int":
-		if err = compare.CompareInt(cerrors.ErrorTypeCmdProbe); err != nil {
+		if err = compare.CompareInt(errorCode); err != nil {
 			return "", err
 		}
 	case "float":
-		if err = compare.CompareFloat(cerrors.ErrorTypeCmdProbe); err != nil {
+		if err = compare.CompareFloat(errorCode); err != nil {
 			return "", err
 		}
 	case "string":
-		if err = compare.CompareString(cerrors.ErrorTypeCmdProbe); err != nil {
+		if err = compare.CompareString(errorCode); err != nil {
+			return "", err
+		}
+	case "duration":
+		if err = compare.CompareDuration(errorCode); err != nil {
+			return "", err
+		}
+	case "semver":
+		if err = compare.CompareSemver(errorCode); err != nil {
+			return "", err
+		}
+	case "json":
+		if err = compare.CompareJSON(errorCode); err != nil {
+			return "", err
+		}
+	case "set":
+		if err = compare.CompareSet(errorCode); err != nil {
 			return "", err
 		}
 	default:
-		return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("comparator type '%s' not supported in the cmd probe", comparator.Type)}
+		return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("comparator type '%s' not supported in the probe", comparator.Type)}
 	}
 	description := fmt.Sprintf("Probe responded with a valid output. Actual and Expected values are '%s' and '%s' respectively", cmdOutput, comparator.Value)
 	return description, nil
@@ -535,6 +518,7 @@ func validateResult(comparator v1alpha1.ComparatorInfo, probeName, cmdOutput str
 // preChaosCmdProbe trigger the cmd probe for prechaos phase
 func preChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
 
+	var err error
 	switch probe.Mode {
 	case "SOT", "Edge":
 
@@ -557,7 +541,7 @@ func preChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 
 		// triggering the cmd probe for the inline mode
 		if reflect.DeepEqual(probe.CmdProbeInputs.Source, v1alpha1.SourceDetails{}) {
-			err = triggerInlineCmdProbe(probe, resultDetails)
+			err = triggerInlineCmdProbe(context.Background(), probe, resultDetails)
 
 			// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 			// it will update the status of all the unrun probes as well
@@ -572,7 +556,7 @@ func preChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			}
 
 			// triggering the cmd probe and storing the output into the out buffer
-			err = triggerSourceCmdProbe(probe, execCommandDetails, clients, resultDetails)
+			err = triggerSourceCmdProbe(context.Background(), probe, execCommandDetails, clients, resultDetails)
 
 			// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 			// it will update the status of all the unrun probes as well
@@ -602,7 +586,9 @@ func preChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			"Phase":          "PreChaos",
 		})
 		if reflect.DeepEqual(probe.CmdProbeInputs.Source, v1alpha1.SourceDetails{}) {
-			go triggerInlineContinuousCmdProbe(probe, clients, resultDetails, chaosDetails)
+			scheduleProbe(probe, clients, resultDetails, chaosDetails, -1, func(ctx context.Context) error {
+				return triggerInlineCmdProbe(ctx, probe, resultDetails)
+			})
 		} else {
 
 			execCommandDetails, err := createHelperPod(probe, resultDetails, clients, chaosDetails)
@@ -611,7 +597,9 @@ func preChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			}
 
 			// trigger the continuous cmd probe
-			go triggerSourceContinuousCmdProbe(probe, execCommandDetails, clients, resultDetails, chaosDetails)
+			scheduleProbe(probe, clients, resultDetails, chaosDetails, -1, func(ctx context.Context) error {
+				return triggerSourceCmdProbe(ctx, probe, execCommandDetails, clients, resultDetails)
+			})
 		}
 
 	}
@@ -621,6 +609,7 @@ func preChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 // postChaosCmdProbe trigger cmd probe for post chaos phase
 func postChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
 
+	var err error
 	switch probe.Mode {
 	case "EOT", "Edge":
 
@@ -643,7 +632,7 @@ func postChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 
 		// triggering the cmd probe for the inline mode
 		if reflect.DeepEqual(probe.CmdProbeInputs.Source, v1alpha1.SourceDetails{}) {
-			err = triggerInlineCmdProbe(probe, resultDetails)
+			err = triggerInlineCmdProbe(context.Background(), probe, resultDetails)
 
 			// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 			// it will update the status of all the unrun probes as well
@@ -658,7 +647,7 @@ func postChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 			}
 
 			// triggering the cmd probe and storing the output into the out buffer
-			err = triggerSourceCmdProbe(probe, execCommandDetails, clients, resultDetails)
+			err = triggerSourceCmdProbe(context.Background(), probe, execCommandDetails, clients, resultDetails)
 
 			// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 			// it will update the status of all the unrun probes as well
@@ -719,7 +708,9 @@ func onChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Result
 			"Phase":          "DuringChaos",
 		})
 		if reflect.DeepEqual(probe.CmdProbeInputs.Source, v1alpha1.SourceDetails{}) {
-			go triggerInlineOnChaosCmdProbe(probe, clients, resultDetails, chaosDetails)
+			scheduleProbe(probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration, func(ctx context.Context) error {
+				return triggerInlineCmdProbe(ctx, probe, resultDetails)
+			})
 		} else {
 
 			execCommandDetails, err := createHelperPod(probe, resultDetails, clients, chaosDetails)
@@ -727,7 +718,9 @@ func onChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Result
 				return err
 			}
 			// trigger the continuous cmd probe
-			go triggerSourceOnChaosCmdProbe(probe, execCommandDetails, clients, resultDetails, chaosDetails)
+			scheduleProbe(probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration, func(ctx context.Context) error {
+				return triggerSourceCmdProbe(ctx, probe, execCommandDetails, clients, resultDetails)
+			})
 		}
 
 	}
@@ -737,6 +730,7 @@ func onChaosCmdProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Result
 // createHelperPod create the helper pod with the source image
 // it will be created if the mode is not inline
 func createHelperPod(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) (litmusexec.PodDetails, error) {
+	var err error
 	// Generate the run_id
 	runID := stringutils.GetRunID()
 	setRunIDForProbe(resultDetails, probe.Name, probe.Type, runID)
@@ -0,0 +1,114 @@
+package comparator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+// IsBaselineCriteria returns true if the criteria compares the value relative to the baseline value
+func IsBaselineCriteria(criteria string) bool {
+	switch criteria {
+	case "withinPercent", "atMostTimes", "atLeastTimes", "equalToBaseline":
+		return true
+	}
+	return false
+}
+
+// CompareBaseline compares the value relative to the baseline value
+// it check for the withinPercent, atMostTimes, atLeastTimes, equalToBaseline operators
+func (model Model) CompareBaseline(errorCode cerrors.ErrorType) error {
+
+	obj := Baseline{}
+	if err := obj.setValues(reflect.ValueOf(model.a).String(), reflect.ValueOf(model.b).String(), reflect.ValueOf(model.baseline).String(), model.operator); err != nil {
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: err.Error()}
+	}
+
+	if model.rc == 1 {
+		log.Infof("[Probe]: {Actual value: %v}, {Baseline value: %v}, {Expected value: %v}, {Operator: %v}", obj.a, obj.baseline, obj.b, model.operator)
+	}
+
+	switch model.operator {
+	case "withinPercent":
+		if !obj.isWithinPercent() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is not within %v%% of the Baseline value: %v", obj.a, obj.b, obj.baseline)}
+		}
+	case "atMostTimes":
+		if !obj.isAtMostTimes() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is more than %vx of the Baseline value: %v", obj.a, obj.b, obj.baseline)}
+		}
+	case "atLeastTimes":
+		if !obj.isAtLeastTimes() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is less than %vx of the Baseline value: %v", obj.a, obj.b, obj.baseline)}
+		}
+	case "equalToBaseline":
+		if !obj.isEqualToBaseline() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is not equal to the Baseline value: %v", obj.rawA, obj.rawBaseline)}
+		}
+	default:
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("criteria '%s' not supported in the probe", model.operator)}
+	}
+	return nil
+}
+
+// Baseline contains operands for baseline comparator check
+type Baseline struct {
+	a           float64
+	b           float64
+	baseline    float64
+	rawA        string
+	rawBaseline string
+}
+
+// SetValues set the values inside Baseline struct
+// equalToBaseline operator compares the raw values, if these are not numbers
+func (f *Baseline) setValues(a, b, baseline, operator string) error {
+	f.rawA, f.rawBaseline = strings.TrimSpace(a), strings.TrimSpace(baseline)
+
+	var errA, errBaseline error
+	f.a, errA = strconv.ParseFloat(f.rawA, 64)
+	f.baseline, errBaseline = strconv.ParseFloat(f.rawBaseline, 64)
+	if operator == "equalToBaseline" {
+		return nil
+	}
+	if errA != nil || errBaseline != nil {
+		return fmt.Errorf("actual value: '%v' and baseline value: '%v' should be numbers for the '%v' criteria", f.rawA, f.rawBaseline, operator)
+	}
+
+	var err error
+	if f.b, err = strconv.ParseFloat(strings.TrimSpace(b), 64); err != nil {
+		return fmt.Errorf("expected value: '%v' should be a number for the '%v' criteria", b, operator)
+	}
+	return nil
+}
+
+// isWithinPercent check for the number should be within the given percentage of the baseline
+func (f *Baseline) isWithinPercent() bool {
+	return math.Abs(f.a-f.baseline) <= math.Abs(f.baseline)*f.b/100
+}
+
+// isAtMostTimes check for the number should not be more than the given multiple of the baseline
+func (f *Baseline) isAtMostTimes() bool {
+	return f.a <= f.baseline*f.b
+}
+
+// isAtLeastTimes check for the number should not be less than the given multiple of the baseline
+func (f *Baseline) isAtLeastTimes() bool {
+	return f.a >= f.baseline*f.b
+}
+
+// isEqualToBaseline check for the value should be equal to the baseline
+// it compares the numbers, if both the values are numbers
+func (f *Baseline) isEqualToBaseline() bool {
+	if _, err := strconv.ParseFloat(f.rawA, 64); err == nil {
+		if _, err := strconv.ParseFloat(f.rawBaseline, 64); err == nil {
+			return f.a == f.baseline
+		}
+	}
+	return f.rawA == f.rawBaseline
+}
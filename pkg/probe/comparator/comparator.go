@@ -8,6 +8,7 @@ type Model struct {
 	operator  string
 	rc        int
 	probeName string
+	baseline  interface{}
 }
 
 // RunCount sets the run counts
@@ -51,3 +52,9 @@ func (model *Model) ProbeName(probeName string) *Model {
 	model.probeName = probeName
 	return model
 }
+
+// Baseline sets the baseline value, which is used by the baseline relative operators
+func (model *Model) Baseline(baseline interface{}) *Model {
+	model.baseline = baseline
+	return model
+}
@@ -0,0 +1,127 @@
+package comparator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+// CompareDuration compares durations (e.g. 250ms, 1m30s) for specific operation
+// it check for the >=, >, <=, <, ==, != operators
+func (model Model) CompareDuration(errorCode cerrors.ErrorType) error {
+
+	obj := Duration{}
+	if err := obj.setValues(reflect.ValueOf(model.a).String(), reflect.ValueOf(model.b).String()); err != nil {
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: err.Error()}
+	}
+
+	if model.rc == 1 {
+		log.Infof("[Probe]: {Actual value: %v}, {Expected value: %v}, {Operator: %v}", obj.a, obj.b, model.operator)
+	}
+
+	switch model.operator {
+	case ">=":
+		if !obj.isGreaterorEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is not greater than or equal to the Expected value: %v", obj.a, obj.b)}
+		}
+	case "<=":
+		if !obj.isLesserorEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is not lesser than or equal to the Expected value: %v", obj.a, obj.b)}
+		}
+	case ">":
+		if !obj.isGreater() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is not greater than the Expected value: %v", obj.a, obj.b)}
+		}
+	case "<":
+		if !obj.isLesser() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is not lesser than the Expected value: %v", obj.a, obj.b)}
+		}
+	case "==":
+		if !obj.isEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is not equal to the Expected value: %v", obj.a, obj.b)}
+		}
+	case "!=":
+		if !obj.isNotEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v should not matched with the Expected value: %v", obj.a, obj.b)}
+		}
+	case "between", "Between":
+		if len(obj.c) < 2 {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Expected value: %v should contains both the lower and upper limits", obj.c)}
+		}
+		if !obj.isBetween() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v doesn't lie in between the Expected range: %v", obj.a, obj.c)}
+		}
+	default:
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("criteria '%s' not supported in the probe", model.operator)}
+	}
+	return nil
+}
+
+// Duration contains operands for duration comparator check
+type Duration struct {
+	a time.Duration
+	b time.Duration
+	c []time.Duration
+}
+
+// SetValues set the values inside Duration struct
+func (d *Duration) setValues(a, b string) error {
+	var err error
+	if d.a, err = time.ParseDuration(strings.TrimSpace(a)); err != nil {
+		return fmt.Errorf("Actual value: '%v' is not a valid duration", a)
+	}
+	c := strings.Split(strings.TrimSpace(b), ",")
+	if len(c) > 1 {
+		for j := range c {
+			x, err := time.ParseDuration(strings.TrimSpace(c[j]))
+			if err != nil {
+				return fmt.Errorf("Expected value: '%v' is not a valid duration", c[j])
+			}
+			d.c = append(d.c, x)
+		}
+		return nil
+	}
+	if d.b, err = time.ParseDuration(strings.TrimSpace(b)); err != nil {
+		return fmt.Errorf("Expected value: '%v' is not a valid duration", b)
+	}
+	return nil
+}
+
+// isGreater check for the first duration should be greater than second duration
+func (d *Duration) isGreater() bool {
+	return d.a > d.b
+}
+
+// isGreaterorEqual check for the first duration should be greater than or equals to the second duration
+func (d *Duration) isGreaterorEqual() bool {
+	return d.a >= d.b
+}
+
+// isLesser check for the first duration should be lesser than second duration
+func (d *Duration) isLesser() bool {
+	return d.a < d.b
+}
+
+// isLesserorEqual check for the first duration should be less than or equals to the second duration
+func (d *Duration) isLesserorEqual() bool {
+	return d.a <= d.b
+}
+
+// isEqual check for the first duration should be equals to the second duration
+func (d *Duration) isEqual() bool {
+	return d.a == d.b
+}
+
+// isNotEqual check for the first duration should be not equals to the second duration
+func (d *Duration) isNotEqual() bool {
+	return d.a != d.b
+}
+
+// isBetween check for the duration should be lie in the given range
+func (d *Duration) isBetween() bool {
+	return d.a >= d.c[0] && d.a <= d.c[1]
+}
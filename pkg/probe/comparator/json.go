@@ -0,0 +1,110 @@
+package comparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+// CompareJSON compares json documents for specific operation
+// it check for the equal, notEqual and subset operations
+func (model Model) CompareJSON(errorCode cerrors.ErrorType) error {
+
+	obj := JSON{}
+	if err := obj.setValues(reflect.ValueOf(model.a).String(), reflect.ValueOf(model.b).String()); err != nil {
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: err.Error()}
+	}
+
+	if model.rc == 1 {
+		log.Infof("[Probe]: {Actual value: %v}, {Expected value: %v}, {Operator: %v}", obj.rawA, obj.rawB, model.operator)
+	}
+
+	switch model.operator {
+	case "equal", "Equal":
+		if !obj.isEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v is not equal to the Expected value: %v", obj.rawA, obj.rawB)}
+		}
+	case "notEqual", "NotEqual":
+		if obj.isEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v should not matched with the Expected value: %v", obj.rawA, obj.rawB)}
+		}
+	case "subset", "Subset":
+		if !obj.isSubset() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v doesn't contain the Expected value: %v", obj.rawA, obj.rawB)}
+		}
+	default:
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("criteria '%s' not supported in the probe", model.operator)}
+	}
+	return nil
+}
+
+// JSON contains operands for json comparator check
+type JSON struct {
+	a    interface{}
+	b    interface{}
+	rawA string
+	rawB string
+}
+
+// SetValues set the values inside JSON struct
+func (j *JSON) setValues(a, b string) error {
+	j.rawA, j.rawB = a, b
+	if err := json.Unmarshal([]byte(a), &j.a); err != nil {
+		return fmt.Errorf("Actual value: '%v' is not a valid json", a)
+	}
+	if err := json.Unmarshal([]byte(b), &j.b); err != nil {
+		return fmt.Errorf("Expected value: '%v' is not a valid json", b)
+	}
+	return nil
+}
+
+// isEqual check for the first document should be deep equal to the second document
+func (j *JSON) isEqual() bool {
+	return reflect.DeepEqual(j.a, j.b)
+}
+
+// isSubset check for the second document should be a subset of the first document
+func (j *JSON) isSubset() bool {
+	return isSubset(j.a, j.b)
+}
+
+// isSubset check for all the fields of expected should be present in the actual with same values
+// each element of the expected list should match with any of the elements of the actual list
+func isSubset(actual, expected interface{}) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range e {
+			if _, ok := a[key]; !ok || !isSubset(a[key], value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, value := range e {
+			found := false
+			for _, item := range a {
+				if isSubset(item, value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(actual, expected)
+	}
+}
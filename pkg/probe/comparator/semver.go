@@ -0,0 +1,196 @@
+package comparator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+// CompareSemver compares semantic versions (e.g. v1.2.3, 1.2.3-rc.1) for specific operation
+// it check for the >=, >, <=, <, ==, != operators
+func (model Model) CompareSemver(errorCode cerrors.ErrorType) error {
+
+	obj := Semver{}
+	if err := obj.setValues(reflect.ValueOf(model.a).String(), reflect.ValueOf(model.b).String()); err != nil {
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: err.Error()}
+	}
+
+	if model.rc == 1 {
+		log.Infof("[Probe]: {Actual value: %v}, {Expected value: %v}, {Operator: %v}", obj.a, obj.b, model.operator)
+	}
+
+	switch model.operator {
+	case ">=":
+		if !obj.isGreaterorEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual version: %v is not greater than or equal to the Expected version: %v", obj.a, obj.b)}
+		}
+	case "<=":
+		if !obj.isLesserorEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual version: %v is not lesser than or equal to the Expected version: %v", obj.a, obj.b)}
+		}
+	case ">":
+		if !obj.isGreater() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual version: %v is not greater than the Expected version: %v", obj.a, obj.b)}
+		}
+	case "<":
+		if !obj.isLesser() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual version: %v is not lesser than the Expected version: %v", obj.a, obj.b)}
+		}
+	case "==":
+		if !obj.isEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual version: %v is not equal to the Expected version: %v", obj.a, obj.b)}
+		}
+	case "!=":
+		if !obj.isNotEqual() {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual version: %v should not matched with the Expected version: %v", obj.a, obj.b)}
+		}
+	default:
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("criteria '%s' not supported in the probe", model.operator)}
+	}
+	return nil
+}
+
+// Semver contains operands for semver comparator check
+type Semver struct {
+	a version
+	b version
+}
+
+// version contains the parsed semantic version
+// build metadata is ignored, as it doesn't take part in the precedence
+type version struct {
+	raw        string
+	numbers    [3]int
+	prerelease []string
+}
+
+func (v version) String() string {
+	return v.raw
+}
+
+// SetValues set the values inside Semver struct
+func (s *Semver) setValues(a, b string) error {
+	var err error
+	if s.a, err = parseVersion(a); err != nil {
+		return fmt.Errorf("Actual value: '%v' is not a valid semantic version", a)
+	}
+	if s.b, err = parseVersion(b); err != nil {
+		return fmt.Errorf("Expected value: '%v' is not a valid semantic version", b)
+	}
+	return nil
+}
+
+// parseVersion parse the semantic version, the leading 'v' and the missing minor/patch versions are allowed
+func parseVersion(raw string) (version, error) {
+	v := version{raw: strings.TrimSpace(raw)}
+	s := strings.TrimPrefix(v.raw, "v")
+	if index := strings.Index(s, "+"); index != -1 {
+		s = s[:index]
+	}
+	if index := strings.Index(s, "-"); index != -1 {
+		v.prerelease = strings.Split(s[index+1:], ".")
+		s = s[:index]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version")
+	}
+	for i := range parts {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version")
+		}
+		v.numbers[i] = n
+	}
+	return v, nil
+}
+
+// compare returns -1, 0 or 1 if the first version is lesser, equal or greater than the second version
+func (s *Semver) compare() int {
+	for i := range s.a.numbers {
+		if s.a.numbers[i] != s.b.numbers[i] {
+			if s.a.numbers[i] < s.b.numbers[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// a version without prerelease has higher precedence than the prerelease version
+	switch {
+	case len(s.a.prerelease) == 0 && len(s.b.prerelease) == 0:
+		return 0
+	case len(s.a.prerelease) == 0:
+		return 1
+	case len(s.b.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(s.a.prerelease) && i < len(s.b.prerelease); i++ {
+		if c := comparePrerelease(s.a.prerelease[i], s.b.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(s.a.prerelease) < len(s.b.prerelease):
+		return -1
+	case len(s.a.prerelease) > len(s.b.prerelease):
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease compares the prerelease identifiers
+// numeric identifiers are compared numerically and have lower precedence than the alphanumeric identifiers
+func comparePrerelease(a, b string) int {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// isGreater check for the first version should be greater than second version
+func (s *Semver) isGreater() bool {
+	return s.compare() > 0
+}
+
+// isGreaterorEqual check for the first version should be greater than or equals to the second version
+func (s *Semver) isGreaterorEqual() bool {
+	return s.compare() >= 0
+}
+
+// isLesser check for the first version should be lesser than second version
+func (s *Semver) isLesser() bool {
+	return s.compare() < 0
+}
+
+// isLesserorEqual check for the first version should be less than or equals to the second version
+func (s *Semver) isLesserorEqual() bool {
+	return s.compare() <= 0
+}
+
+// isEqual check for the first version should be equals to the second version
+func (s *Semver) isEqual() bool {
+	return s.compare() == 0
+}
+
+// isNotEqual check for the first version should be not equals to the second version
+func (s *Semver) isNotEqual() bool {
+	return s.compare() != 0
+}
@@ -0,0 +1,112 @@
+package comparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+)
+
+// CompareSet compares sets, provided as comma separated or json lists, for specific operation
+// it check for the containsAll, containsAny and disjoint operations
+func (model Model) CompareSet(errorCode cerrors.ErrorType) error {
+
+	obj := Set{}
+	obj.setValues(reflect.ValueOf(model.a).String(), reflect.ValueOf(model.b).String())
+
+	if model.rc == 1 {
+		log.Infof("[Probe]: {Actual value: %v}, {Expected value: %v}, {Operator: %v}", obj.a, obj.b, model.operator)
+	}
+
+	switch model.operator {
+	case "containsAll", "ContainsAll":
+		if missing := obj.missing(); len(missing) != 0 {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v doesn't contain all the Expected values: %v, missing: %v", obj.a, obj.b, missing)}
+		}
+	case "containsAny", "ContainsAny":
+		if len(obj.common()) == 0 {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v doesn't contain any of the Expected values: %v", obj.a, obj.b)}
+		}
+	case "disjoint", "Disjoint":
+		if common := obj.common(); len(common) != 0 {
+			return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("Probe responded with an invalid output. Actual value: %v should not contain any of the Expected values: %v, found: %v", obj.a, obj.b, common)}
+		}
+	default:
+		return cerrors.Error{ErrorCode: errorCode, Target: model.probeName, Reason: fmt.Sprintf("criteria '%s' not supported in the probe", model.operator)}
+	}
+	return nil
+}
+
+// Set contains operands for set comparator check
+type Set struct {
+	a []string
+	b []string
+}
+
+// SetValues set the values inside Set struct
+func (s *Set) setValues(a, b string) {
+	s.a = parseList(a)
+	s.b = parseList(b)
+}
+
+// parseList parse the json list or comma separated list
+// the elements of the json list, which are not strings, are converted into their json form
+func parseList(value string) []string {
+	value = strings.TrimSpace(value)
+	var list []interface{}
+	if err := json.Unmarshal([]byte(value), &list); err == nil {
+		var items []string
+		for _, item := range list {
+			if str, ok := item.(string); ok {
+				items = append(items, str)
+				continue
+			}
+			raw, _ := json.Marshal(item)
+			items = append(items, string(raw))
+		}
+		return items
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// missing returns the elements of the second set, which are not present inside the first set
+func (s *Set) missing() []string {
+	var missing []string
+	for _, item := range s.b {
+		if !containsItem(s.a, item) {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
+
+// common returns the elements, which are present inside both the sets
+func (s *Set) common() []string {
+	var common []string
+	for _, item := range s.b {
+		if containsItem(s.a, item) {
+			common = append(common, item)
+		}
+	}
+	return common
+}
+
+// containsItem check for the item should be present inside the list
+func containsItem(list []string, item string) bool {
+	for i := range list {
+		if list[i] == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package probe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/sirupsen/logrus"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// compositeProbeInputs contains the inputs required for the composite probe
+// it is provided inside the data field of the probe, in yaml/json format
+type compositeProbeInputs struct {
+	// Probes contains the names of the probes, whose verdicts are combined
+	Probes []string `json:"probes,omitempty"`
+	// Logic to combine the verdicts, supports: and, or, atLeast
+	Logic string `json:"logic,omitempty"`
+	// MinPassed is the minimum number of probes, which should pass for the atLeast logic
+	MinPassed int `json:"minPassed,omitempty"`
+}
+
+// prepareCompositeProbe contains the steps to prepare the composite probe
+// composite probe combines the verdicts of the referenced probes with and/or/atLeast logic
+func prepareCompositeProbe(probe v1alpha1.ProbeAttributes, chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, phase string) error {
+
+	switch strings.ToLower(phase) {
+	case "prechaos":
+		switch strings.ToLower(probe.Mode) {
+		case "sot", "edge":
+			if err := evaluateCompositeProbe(probe, resultDetails, "PreChaos"); err != nil {
+				return err
+			}
+		}
+	case "postchaos":
+		switch strings.ToLower(probe.Mode) {
+		case "eot", "edge", "continuous", "onchaos":
+			if err := evaluateCompositeProbe(probe, resultDetails, "PostChaos"); err != nil {
+				return err
+			}
+		}
+	case "duringchaos":
+		// composite probe is evaluated only in the prechaos and postchaos phases
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeCompositeProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("phase '%s' not supported in the composite probe", phase)}
+	}
+	return nil
+}
+
+// evaluateCompositeProbe combines the latest verdicts of the referenced probes and marks the verdict of the composite probe
+func evaluateCompositeProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, phase string) error {
+
+	//DISPLAY THE COMPOSITE PROBE INFO
+	log.InfoWithValues("[Probe]: The composite probe information is as follows", logrus.Fields{
+		"Name":           probe.Name,
+		"Inputs":         probe.Data,
+		"Run Properties": probe.RunProperties,
+		"Mode":           probe.Mode,
+		"Phase":          phase,
+	})
+
+	err := triggerCompositeProbe(probe, resultDetails)
+
+	// failing the probe, if the combined verdict of the referenced probes is failed
+	return markedVerdictInEnd(err, resultDetails, probe, phase)
+}
+
+// triggerCompositeProbe combines the latest verdicts of the referenced probes as per the given logic
+func triggerCompositeProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) error {
+	inputs, err := getCompositeProbeInputs(probe)
+	if err != nil {
+		return err
+	}
+
+	var passed int
+	var failures []string
+	for _, name := range inputs.Probes {
+		probeDetails := getProbeByName(name, resultDetails.ProbeDetails)
+		switch {
+		case probeDetails == nil:
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeCompositeProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("referenced probe '%s' not found", name)}
+		case probeDetails.LastVerdict == v1alpha1.ProbeVerdictPassed:
+			passed++
+		case probeDetails.LastVerdict == v1alpha1.ProbeVerdictFailed:
+			failures = append(failures, fmt.Sprintf("{probe: %s, reason: %s}", name, getDescription(probeDetails.LastError)))
+		default:
+			failures = append(failures, fmt.Sprintf("{probe: %s, reason: probe is not evaluated yet}", name))
+		}
+	}
+
+	minPassed := len(inputs.Probes)
+	switch strings.ToLower(inputs.Logic) {
+	case "or":
+		minPassed = 1
+	case "atleast":
+		minPassed = inputs.MinPassed
+	}
+
+	log.Infof("[Probe]: %v out of %v probes are passed, required: %v", passed, len(inputs.Probes), minPassed)
+	if passed < minPassed {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeCompositeProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("%v out of %v probes are passed, required: %v, failures: [%s]", passed, len(inputs.Probes), minPassed, strings.Join(failures, ","))}
+	}
+	setProbeDescription(resultDetails, probe, fmt.Sprintf("%v out of %v probes are passed, required: %v", passed, len(inputs.Probes), minPassed))
+	return nil
+}
+
+// getCompositeProbeInputs parse the composite probe inputs from the data field of the probe
+func getCompositeProbeInputs(probe v1alpha1.ProbeAttributes) (compositeProbeInputs, error) {
+	inputs := compositeProbeInputs{}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &inputs); err != nil {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeCompositeProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to parse the composite probe inputs from data, err: %v", err)}
+	}
+	if len(inputs.Probes) == 0 {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeCompositeProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: "no probes are referenced by the composite probe"}
+	}
+
+	switch strings.ToLower(inputs.Logic) {
+	case "", "and", "or":
+	case "atleast":
+		if inputs.MinPassed < 1 || inputs.MinPassed > len(inputs.Probes) {
+			return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeCompositeProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("minPassed should be between 1 and %v for the atLeast logic", len(inputs.Probes))}
+		}
+	default:
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeCompositeProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("logic '%s' not supported in the composite probe", inputs.Logic)}
+	}
+	return inputs, nil
+}
+
+// prepareCompositeProbes marks the probes referenced by the composite probes and moves the composite probes in the end,
+// so that the composite probes are evaluated after the referenced probes in every phase.
+// the referenced probes shouldn't stop the experiment on failure, it is governed by the stopOnFailure of the composite probe
+func prepareCompositeProbes(probes []v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) ([]v1alpha1.ProbeAttributes, error) {
+	var composites, others []v1alpha1.ProbeAttributes
+	members := map[string]string{}
+	for _, probe := range probes {
+		if !isCompositeProbe(probe) {
+			others = append(others, probe)
+			continue
+		}
+		composites = append(composites, probe)
+		inputs, err := getCompositeProbeInputs(probe)
+		if err != nil {
+			continue
+		}
+		for _, name := range inputs.Probes {
+			members[name] = probe.Name
+		}
+	}
+	if len(composites) == 0 {
+		return probes, nil
+	}
+
+	for _, probe := range others {
+		composite, ok := members[probe.Name]
+		if !ok {
+			continue
+		}
+		if probe.RunProperties.StopOnFailure {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeCompositeProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("probe is referenced by the '%s' composite probe, stopOnFailure should be set on the composite probe instead", composite)}
+		}
+		if probeDetails := getProbeByName(probe.Name, resultDetails.ProbeDetails); probeDetails != nil {
+			probeDetails.Composite = composite
+		}
+	}
+	return append(others, composites...), nil
+}
+
+// isCompositeProbe checks whether the probe is a composite probe
+func isCompositeProbe(probe v1alpha1.ProbeAttributes) bool {
+	return strings.ToLower(probe.Type) == "compositeprobe"
+}
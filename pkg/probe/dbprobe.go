@@ -0,0 +1,507 @@
+package probe
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	cmp "github.com/litmuschaos/litmus-go/pkg/probe/comparator"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/retry"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// dbProbeInputs contains the inputs required for the db probe
+// it is provided inside the data field of the probe, in yaml/json format
+type dbProbeInputs struct {
+	// Engine of the database, supports: postgres, mysql, redis
+	Engine string `json:"engine,omitempty"`
+	// Host and Port of the database
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+	// Database name for postgres/mysql and database index for redis
+	Database string `json:"database,omitempty"`
+	// Credentials contains the secret details, which contain the database credentials
+	Credentials probeCredentials `json:"credentials,omitempty"`
+	// SSLMode for the postgres connection, defaults to disable
+	SSLMode string `json:"sslMode,omitempty"`
+	// Query for postgres/mysql or command for redis (e.g. GET key)
+	// only the connection is checked, if it is not provided
+	Query string `json:"query,omitempty"`
+	// Comparator check for the first column of the first row of the query result
+	Comparator *v1alpha1.ComparatorInfo `json:"comparator,omitempty"`
+	// MaxConnectLatency and MaxQueryLatency are the maximum allowed latencies, e.g. 500ms
+	MaxConnectLatency string `json:"maxConnectLatency,omitempty"`
+	MaxQueryLatency   string `json:"maxQueryLatency,omitempty"`
+}
+
+// probeCredentials contains the details of the secret, which contains the credentials of the probe target
+type probeCredentials struct {
+	SecretName string `json:"secretName,omitempty"`
+	// Namespace of the secret, defaults to the chaos namespace
+	Namespace string `json:"namespace,omitempty"`
+	// UsernameKey and PasswordKey are the keys inside the secret, defaults to username and password
+	UsernameKey string `json:"usernameKey,omitempty"`
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+// dbResult contains the outcome of the db probe query
+type dbResult struct {
+	value          string
+	connectLatency time.Duration
+	queryLatency   time.Duration
+}
+
+// prepareDBProbe contains the steps to prepare the db probe
+// db probe connects to the database, runs the query and compares the result & latencies
+func prepareDBProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, phase string) error {
+
+	switch strings.ToLower(phase) {
+	case "prechaos":
+		if err := preChaosDBProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "postchaos":
+		if err := postChaosDBProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "duringchaos":
+		onChaosDBProbe(probe, resultDetails, clients, chaosDetails)
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeDBProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("phase '%s' not supported in the db probe", phase)}
+	}
+	return nil
+}
+
+// preChaosDBProbe trigger the db probe for prechaos phase
+func preChaosDBProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "sot", "edge":
+
+		//DISPLAY THE DB PROBE INFO
+		log.InfoWithValues("[Probe]: The db probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// triggering the db probe
+		err := triggerDBProbe(context.Background(), probe, clients, resultDetails, chaosDetails)
+
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PreChaos"); err != nil {
+			return err
+		}
+	case "continuous":
+
+		//DISPLAY THE DB PROBE INFO
+		log.InfoWithValues("[Probe]: The db probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, -1, func(ctx context.Context) error {
+			return triggerDBProbe(ctx, probe, clients, resultDetails, chaosDetails)
+		})
+	}
+	return nil
+}
+
+// postChaosDBProbe trigger the db probe for postchaos phase
+func postChaosDBProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "eot", "edge":
+
+		//DISPLAY THE DB PROBE INFO
+		log.InfoWithValues("[Probe]: The db probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PostChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// triggering the db probe
+		err := triggerDBProbe(context.Background(), probe, clients, resultDetails, chaosDetails)
+
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	case "continuous", "onchaos":
+		// it will check for the error, It will detect the error if any error encountered in probe during chaos
+		err := checkForErrorInContinuousProbe(resultDetails, probe.Name)
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onChaosDBProbe trigger the db probe for DuringChaos phase
+func onChaosDBProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) {
+
+	switch strings.ToLower(probe.Mode) {
+	case "onchaos":
+
+		//DISPLAY THE DB PROBE INFO
+		log.InfoWithValues("[Probe]: The db probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "DuringChaos",
+		})
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration, func(ctx context.Context) error {
+			return triggerDBProbe(ctx, probe, clients, resultDetails, chaosDetails)
+		})
+	}
+}
+
+// triggerDBProbe connects to the database, runs the query and compares the result & latencies
+func triggerDBProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, clients clients.ClientSets, resultDetails *types.ResultDetails, chaosDetails *types.ChaosDetails) error {
+	inputs, err := getDBProbeInputs(probe)
+	if err != nil {
+		return err
+	}
+	username, password, err := getProbeCredentials(probe.Name, inputs.Credentials, clients, chaosDetails.ChaosNamespace, cerrors.ErrorTypeDBProbe)
+	if err != nil {
+		return err
+	}
+
+	var description string
+	// it will retry for some retry count, in each iteration of try it contains following things
+	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
+	// for a timeout, it will run the query, if it fails wait for the interval and again execute the query until timeout expires
+	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
+		Timeout(int64(probe.RunProperties.ProbeTimeout)).
+		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
+		TryWithTimeout(func(attempt uint) error {
+			ctx, cancel := context.WithTimeout(ctx, time.Duration(probe.RunProperties.ProbeTimeout)*time.Millisecond)
+			defer cancel()
+
+			var result dbResult
+			var err error
+			switch inputs.Engine {
+			case "redis":
+				result, err = runRedisCommand(ctx, inputs, username, password)
+			default:
+				result, err = runSQLQuery(ctx, inputs, username, password)
+			}
+			if err != nil {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeDBProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
+			}
+
+			rc := getAndIncrementRunCount(resultDetails, probe.Name)
+			if description, err = validateDBResult(probe, inputs, result, rc, resultDetails); err != nil {
+				log.Errorf("The %v db probe has been Failed, err: %v", probe.Name, err)
+				return err
+			}
+			// storing the query result and the named outputs inside the probe artifacts
+			return registerProbeArtifacts(probe, resultDetails, result.value, cerrors.ErrorTypeDBProbe)
+		}); err != nil {
+		return err
+	}
+	setProbeDescription(resultDetails, probe, description)
+	return nil
+}
+
+// validateDBResult compares the connect & query latencies and the query result with the expected criteria
+func validateDBResult(probe v1alpha1.ProbeAttributes, inputs dbProbeInputs, result dbResult, rc int, resultDetails *types.ResultDetails) (string, error) {
+	if inputs.MaxConnectLatency != "" {
+		if err := cmp.RunCount(rc).
+			FirstValue(result.connectLatency.String()).
+			SecondValue(inputs.MaxConnectLatency).
+			Criteria("<=").
+			ProbeName(probe.Name).
+			CompareDuration(cerrors.ErrorTypeDBProbe); err != nil {
+			return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeDBProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("connect latency check failed, %s", getDescription(err))}
+		}
+	}
+	if inputs.MaxQueryLatency != "" && inputs.Query != "" {
+		if err := cmp.RunCount(rc).
+			FirstValue(result.queryLatency.String()).
+			SecondValue(inputs.MaxQueryLatency).
+			Criteria("<=").
+			ProbeName(probe.Name).
+			CompareDuration(cerrors.ErrorTypeDBProbe); err != nil {
+			return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeDBProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("query latency check failed, %s", getDescription(err))}
+		}
+	}
+
+	description := fmt.Sprintf("Database responded within the expected latencies. Connect and query latencies are '%v' and '%v' respectively", result.connectLatency, result.queryLatency)
+	if inputs.Comparator != nil && inputs.Query != "" {
+		if _, err := validateResult(*inputs.Comparator, probe.Name, defaultBaselineKey, result.value, rc, resultDetails, cerrors.ErrorTypeDBProbe); err != nil {
+			return "", err
+		}
+		description = fmt.Sprintf("%s. Actual and Expected values are '%s' and '%s' respectively", description, result.value, inputs.Comparator.Value)
+	}
+	return description, nil
+}
+
+// runSQLQuery connects to the postgres/mysql database and runs the query
+// it returns the first column of the first row of the query result
+func runSQLQuery(ctx context.Context, inputs dbProbeInputs, username, password string) (dbResult, error) {
+	result := dbResult{}
+	address := net.JoinHostPort(inputs.Host, strconv.Itoa(inputs.Port))
+
+	var driver, dsn string
+	switch inputs.Engine {
+	case "postgres":
+		driver = "postgres"
+		dsn = (&url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(username, password),
+			Host:     address,
+			Path:     "/" + inputs.Database,
+			RawQuery: url.Values{"sslmode": []string{inputs.SSLMode}}.Encode(),
+		}).String()
+	case "mysql":
+		driver = "mysql"
+		config := mysql.NewConfig()
+		config.User, config.Passwd = username, password
+		config.Net, config.Addr = "tcp", address
+		config.DBName = inputs.Database
+		dsn = config.FormatDSN()
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return result, fmt.Errorf("unable to open the %v connection, err: %v", inputs.Engine, err)
+	}
+	defer db.Close()
+
+	startTime := time.Now()
+	if err := db.PingContext(ctx); err != nil {
+		return result, fmt.Errorf("unable to connect to the %v database at %v, err: %v", inputs.Engine, address, err)
+	}
+	result.connectLatency = time.Since(startTime)
+	if inputs.Query == "" {
+		return result, nil
+	}
+
+	startTime = time.Now()
+	rows, err := db.QueryContext(ctx, inputs.Query)
+	if err != nil {
+		return result, fmt.Errorf("unable to run the query, err: %v", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		columns, err := rows.Columns()
+		if err != nil {
+			return result, fmt.Errorf("unable to get the columns of the query result, err: %v", err)
+		}
+		values := make([]interface{}, len(columns))
+		for i := range values {
+			values[i] = new(sql.NullString)
+		}
+		if err := rows.Scan(values...); err != nil {
+			return result, fmt.Errorf("unable to read the query result, err: %v", err)
+		}
+		if len(values) != 0 {
+			result.value = values[0].(*sql.NullString).String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("unable to read the query result, err: %v", err)
+	}
+	result.queryLatency = time.Since(startTime)
+	return result, nil
+}
+
+// runRedisCommand connects to the redis and runs the command
+// it returns the first element of the command result, if the result is a list
+func runRedisCommand(ctx context.Context, inputs dbProbeInputs, username, password string) (dbResult, error) {
+	result := dbResult{}
+	address := net.JoinHostPort(inputs.Host, strconv.Itoa(inputs.Port))
+
+	db := 0
+	if inputs.Database != "" {
+		var err error
+		if db, err = strconv.Atoi(inputs.Database); err != nil {
+			return result, fmt.Errorf("redis database should be a number, provided: '%v'", inputs.Database)
+		}
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:       address,
+		Username:   username,
+		Password:   password,
+		DB:         db,
+		MaxRetries: -1,
+	})
+	defer client.Close()
+
+	startTime := time.Now()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return result, fmt.Errorf("unable to connect to the redis at %v, err: %v", address, err)
+	}
+	result.connectLatency = time.Since(startTime)
+	if inputs.Query == "" {
+		return result, nil
+	}
+
+	fields, err := splitCommandArgs(inputs.Query)
+	if err != nil {
+		return result, fmt.Errorf("unable to parse the command, err: %v", err)
+	}
+	var args []interface{}
+	for _, arg := range fields {
+		args = append(args, arg)
+	}
+	startTime = time.Now()
+	value, err := client.Do(ctx, args...).Result()
+	if err != nil && err != redis.Nil {
+		return result, fmt.Errorf("unable to run the command, err: %v", err)
+	}
+	result.queryLatency = time.Since(startTime)
+
+	switch v := value.(type) {
+	case nil:
+	case []interface{}:
+		if len(v) != 0 {
+			result.value = fmt.Sprint(v[0])
+		}
+	default:
+		result.value = fmt.Sprint(v)
+	}
+	return result, nil
+}
+
+// getDBProbeInputs parse the db probe inputs from the data field of the probe
+func getDBProbeInputs(probe v1alpha1.ProbeAttributes) (dbProbeInputs, error) {
+	inputs := dbProbeInputs{}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &inputs); err != nil {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeDBProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to parse the db probe inputs from data, err: %v", err)}
+	}
+	if inputs.Host == "" {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeDBProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: "host is required for the db probe"}
+	}
+
+	// setting the defaults as per the database engine
+	inputs.Engine = strings.ToLower(inputs.Engine)
+	switch inputs.Engine {
+	case "postgres", "postgresql":
+		inputs.Engine = "postgres"
+		if inputs.Port == 0 {
+			inputs.Port = 5432
+		}
+		if inputs.SSLMode == "" {
+			inputs.SSLMode = "disable"
+		}
+	case "mysql":
+		if inputs.Port == 0 {
+			inputs.Port = 3306
+		}
+	case "redis":
+		if inputs.Port == 0 {
+			inputs.Port = 6379
+		}
+	default:
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeDBProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("database engine '%s' not supported in the db probe", inputs.Engine)}
+	}
+	if inputs.Credentials.UsernameKey == "" {
+		inputs.Credentials.UsernameKey = "username"
+	}
+	if inputs.Credentials.PasswordKey == "" {
+		inputs.Credentials.PasswordKey = "password"
+	}
+	return inputs, nil
+}
+
+// getProbeCredentials fetch the username and password from the secret
+// it returns empty credentials, if secret is not provided
+func getProbeCredentials(probeName string, credentials probeCredentials, clients clients.ClientSets, chaosNamespace string, errorCode cerrors.ErrorType) (string, string, error) {
+	if credentials.SecretName == "" {
+		return "", "", nil
+	}
+	namespace := credentials.Namespace
+	if namespace == "" {
+		namespace = chaosNamespace
+	}
+
+	secret, err := clients.KubeClient.CoreV1().Secrets(namespace).Get(context.Background(), credentials.SecretName, v1.GetOptions{})
+	if err != nil {
+		return "", "", cerrors.Error{ErrorCode: errorCode, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("unable to get the %v secret in %v namespace, err: %v", credentials.SecretName, namespace, err)}
+	}
+	return string(secret.Data[credentials.UsernameKey]), string(secret.Data[credentials.PasswordKey]), nil
+}
+
+// splitCommandArgs splits the command into the arguments like a shell does
+// the quoted arguments can contain spaces, backslash escapes the next character outside the single quotes
+func splitCommandArgs(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in the command")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in the command", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,122 @@
+package probe
+
+import (
+	"fmt"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/palantir/stacktrace"
+)
+
+// getEvaluationPolicy returns the evaluation policy of the probe, provided via the PROBE_EVALUATION_POLICIES env
+// it returns nil if the evaluation policy is not provided
+func getEvaluationPolicy(probe v1alpha1.ProbeAttributes, chaosDetails *types.ChaosDetails) *types.EvaluationPolicy {
+	if chaosDetails == nil {
+		return nil
+	}
+	policy, ok := chaosDetails.ProbeEvaluationPolicies[probe.Name]
+	if !ok {
+		return nil
+	}
+	return &policy
+}
+
+// recordProbeIteration records the outcome of an iteration of the continuous and onchaos probes
+// it returns the error as it is, if the evaluation policy is not provided.
+// otherwise it returns the error only if the failed iterations exceed the max consecutive failures,
+// the success rate is evaluated at the end of the chaos
+func recordProbeIteration(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, chaosDetails *types.ChaosDetails, err error) error {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+	probeDetails := getProbeByName(probe.Name, resultDetails.ProbeDetails)
+	if probeDetails == nil {
+		return err
+	}
+	if probeDetails.Evaluation == nil {
+		policy := getEvaluationPolicy(probe, chaosDetails)
+		if policy == nil {
+			return err
+		}
+		probeDetails.Evaluation = &types.EvaluationDetails{
+			SuccessRate:            policy.SuccessRate,
+			MaxConsecutiveFailures: policy.MaxConsecutiveFailures,
+		}
+	}
+
+	evaluation := probeDetails.Evaluation
+	evaluation.Iterations++
+	if err == nil {
+		evaluation.PassedIterations++
+		evaluation.ConsecutiveFailures = 0
+		return nil
+	}
+	evaluation.ConsecutiveFailures++
+	evaluation.LastError = err
+	log.Warnf("[Probe]: The %v probe iteration has been Failed, passed iterations: %v/%v, err: %v", probe.Name, evaluation.PassedIterations, evaluation.Iterations, getDescription(err))
+
+	if evaluation.MaxConsecutiveFailures > 0 && evaluation.ConsecutiveFailures > evaluation.MaxConsecutiveFailures {
+		return cerrors.Error{ErrorCode: cerrors.GetErrorType(stacktrace.RootCause(err)), Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("%v consecutive iterations failed, max allowed: %v, %v, last error: %v", evaluation.ConsecutiveFailures, evaluation.MaxConsecutiveFailures, getIterationSummary(evaluation), getDescription(err))}
+	}
+	return nil
+}
+
+// evaluateSuccessRate evaluates the success rate of the iterations of the continuous and onchaos probes
+// and records the ratio and counts inside the probe status
+func evaluateSuccessRate(probeDetails *types.ProbeDetails) error {
+	evaluation := probeDetails.Evaluation
+	if evaluation.Iterations == 0 {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{name: %v}", probeDetails.Name), Reason: "no iteration of the probe is completed"}
+	}
+
+	rate := float64(evaluation.PassedIterations) * 100 / float64(evaluation.Iterations)
+	if rate < evaluation.SuccessRate {
+		errorCode := cerrors.ErrorTypeGeneric
+		description := "no error"
+		if evaluation.LastError != nil {
+			errorCode = cerrors.GetErrorType(stacktrace.RootCause(evaluation.LastError))
+			description = getDescription(evaluation.LastError)
+		}
+		return cerrors.Error{ErrorCode: errorCode, Target: fmt.Sprintf("{name: %v}", probeDetails.Name), Reason: fmt.Sprintf("success rate is %.2f%%, required: %v%%, %v, last error: %v", rate, evaluation.SuccessRate, getIterationSummary(evaluation), description)}
+	}
+	probeDetails.Status.Description = fmt.Sprintf("success rate is %.2f%%, required: %v%%, %v", rate, evaluation.SuccessRate, getIterationSummary(evaluation))
+	return nil
+}
+
+// getIterationSummary returns the counts of the iterations
+func getIterationSummary(evaluation *types.EvaluationDetails) string {
+	return fmt.Sprintf("{iterations: %v, passed: %v, failed: %v}", evaluation.Iterations, evaluation.PassedIterations, evaluation.Iterations-evaluation.PassedIterations)
+}
+
+// EvaluationSummary contains the outcome of the iterations of the probe, evaluated with the evaluation policy
+type EvaluationSummary struct {
+	Iterations          int     `json:"iterations"`
+	Passed              int     `json:"passed"`
+	Failed              int     `json:"failed"`
+	SuccessRate         float64 `json:"successRate"`
+	RequiredSuccessRate float64 `json:"requiredSuccessRate,omitempty"`
+}
+
+// GetProbeEvaluations returns the outcome of the iterations of the probes with the evaluation policy, keyed by the probe name
+func GetProbeEvaluations(resultDetails *types.ResultDetails) map[string]EvaluationSummary {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+	evaluations := map[string]EvaluationSummary{}
+	for _, probe := range resultDetails.ProbeDetails {
+		if probe.Evaluation == nil {
+			continue
+		}
+		summary := EvaluationSummary{
+			Iterations:          probe.Evaluation.Iterations,
+			Passed:              probe.Evaluation.PassedIterations,
+			Failed:              probe.Evaluation.Iterations - probe.Evaluation.PassedIterations,
+			RequiredSuccessRate: probe.Evaluation.SuccessRate,
+		}
+		if summary.Iterations != 0 {
+			summary.SuccessRate = float64(summary.Passed) * 100 / float64(summary.Iterations)
+		}
+		evaluations[probe.Name] = summary
+	}
+	return evaluations
+}
@@ -2,7 +2,9 @@ package probe
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"reflect"
 	"strconv"
@@ -16,13 +18,16 @@ import (
 	"github.com/litmuschaos/litmus-go/pkg/cerrors"
 	"github.com/litmuschaos/litmus-go/pkg/clients"
 	"github.com/litmuschaos/litmus-go/pkg/log"
-	"github.com/litmuschaos/litmus-go/pkg/math"
 	cmp "github.com/litmuschaos/litmus-go/pkg/probe/comparator"
 	"github.com/litmuschaos/litmus-go/pkg/types"
 	"github.com/litmuschaos/litmus-go/pkg/utils/retry"
 	"github.com/sirupsen/logrus"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
+// maxResponseBodySize is the maximum size of the response body, which is stored inside the probe artifacts
+const maxResponseBodySize = 1 << 20
+
 // prepareHTTPProbe contains the steps to prepare the http probe
 // http probe can be used to add the probe which will send a request to given url and match the status code
 func prepareHTTPProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, phase string) error {
@@ -45,8 +50,9 @@ func prepareHTTPProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets
 }
 
 // triggerHTTPProbe run the http probe command
-func triggerHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) error {
+func triggerHTTPProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) error {
 
+	var err error
 	// It parses the templated url and return normal string
 	// if command doesn't have template, it will return the same command
 	probe.HTTPProbeInputs.URL, err = parseCommand(probe.HTTPProbeInputs.URL, resultDetails)
@@ -77,7 +83,7 @@ func triggerHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			"ResponseCode":    probe.HTTPProbeInputs.Method.Get.ResponseCode,
 			"ResponseTimeout": probe.RunProperties.ProbeTimeout,
 		})
-		return httpGet(probe, client, resultDetails)
+		return httpGet(ctx, probe, client, resultDetails)
 	case "Post":
 		log.InfoWithValues("[Probe]: HTTP Post method informations", logrus.Fields{
 			"Name":            probe.Name,
@@ -87,7 +93,7 @@ func triggerHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			"ContentType":     probe.HTTPProbeInputs.Method.Post.ContentType,
 			"ResponseTimeout": probe.RunProperties.ProbeTimeout,
 		})
-		return httpPost(probe, client, resultDetails)
+		return httpPost(ctx, probe, client, resultDetails)
 	}
 	return nil
 }
@@ -102,20 +108,26 @@ func getHTTPMethodType(httpMethod v1alpha1.HTTPMethod) string {
 }
 
 // httpGet send the http Get request to the given URL and verify the response code to follow the specified criteria
-func httpGet(probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails *types.ResultDetails) error {
+func httpGet(ctx context.Context, probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails *types.ResultDetails) error {
 	var description string
 
 	// it will retry for some retry count, in each iteration of try it contains following things
 	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
 	// for a timeout, it will run the command, if it fails wait for the interval and again execute the command until timeout expires
 	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
 		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
 		Try(func(attempt uint) error {
 			// getting the response from the given url
-			resp, err := client.Get(probe.HTTPProbeInputs.URL)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.HTTPProbeInputs.URL, nil)
+			if err != nil {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeHttpProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
+			}
+			resp, err := client.Do(req)
 			if err != nil {
 				return cerrors.Error{ErrorCode: cerrors.ErrorTypeHttpProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
 			}
+			defer resp.Body.Close()
 
 			code := strconv.Itoa(resp.StatusCode)
 			rc := getAndIncrementRunCount(resultDetails, probe.Name)
@@ -131,7 +143,8 @@ func httpGet(probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails
 				return err
 			}
 			description = fmt.Sprintf("The URL %s did respond with correct status code. Actual and Expected status codes are '%s' and '%s' respectively", probe.HTTPProbeInputs.URL, code, probe.HTTPProbeInputs.Method.Get.ResponseCode)
-			return nil
+			// validating the response body and storing it inside the probe artifacts
+			return evaluateHTTPResponse(probe, resultDetails, resp, rc)
 		}); err != nil {
 		return err
 	}
@@ -140,7 +153,7 @@ func httpGet(probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails
 }
 
 // httpPost send the http post request to the given URL
-func httpPost(probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails *types.ResultDetails) error {
+func httpPost(ctx context.Context, probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails *types.ResultDetails) error {
 	body, err := getHTTPBody(probe.HTTPProbeInputs.Method.Post, probe.Name)
 	if err != nil {
 		return err
@@ -152,12 +165,19 @@ func httpPost(probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails
 	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
 	// for a timeout, it will run the command, if it fails wait for the interval and again execute the command until timeout expires
 	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
 		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
 		Try(func(attempt uint) error {
-			resp, err := client.Post(probe.HTTPProbeInputs.URL, probe.HTTPProbeInputs.Method.Post.ContentType, strings.NewReader(body))
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, probe.HTTPProbeInputs.URL, strings.NewReader(body))
 			if err != nil {
 				return cerrors.Error{ErrorCode: cerrors.ErrorTypeHttpProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
 			}
+			req.Header.Set("Content-Type", probe.HTTPProbeInputs.Method.Post.ContentType)
+			resp, err := client.Do(req)
+			if err != nil {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeHttpProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
+			}
+			defer resp.Body.Close()
 			code := strconv.Itoa(resp.StatusCode)
 			rc := getAndIncrementRunCount(resultDetails, probe.Name)
 
@@ -172,7 +192,8 @@ func httpPost(probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails
 				return err
 			}
 			description = fmt.Sprintf("The URL %s did respond with correct status code. Actual and Expected status codes are '%s' and '%s' respectively", probe.HTTPProbeInputs.URL, code, probe.HTTPProbeInputs.Method.Get.ResponseCode)
-			return nil
+			// validating the response body and storing it inside the probe artifacts
+			return evaluateHTTPResponse(probe, resultDetails, resp, rc)
 		}); err != nil {
 		return err
 	}
@@ -180,6 +201,39 @@ func httpPost(probe v1alpha1.ProbeAttributes, client *http.Client, resultDetails
 	return nil
 }
 
+// evaluateHTTPResponse compares the http response body with the body comparator, if provided
+// and stores the response body and the named outputs extracted from it inside the probe artifacts
+func evaluateHTTPResponse(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, resp *http.Response, rc int) error {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	if err != nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeHttpProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to read the response body, err: %v", err)}
+	}
+	response := strings.TrimSpace(string(body))
+
+	if comparator := getBodyComparator(probe); comparator != nil {
+		if _, err := validateResult(*comparator, probe.Name, defaultBaselineKey, response, rc, resultDetails, cerrors.ErrorTypeHttpProbe); err != nil {
+			log.Errorf("The %v http probe response body has Failed, err: %v", probe.Name, err)
+			return err
+		}
+	}
+	return registerProbeArtifacts(probe, resultDetails, response, cerrors.ErrorTypeHttpProbe)
+}
+
+// getBodyComparator parse the comparator for the response body from the data field of the probe
+// it is provided under the bodyComparator key, in yaml/json format
+func getBodyComparator(probe v1alpha1.ProbeAttributes) *v1alpha1.ComparatorInfo {
+	data := struct {
+		BodyComparator *v1alpha1.ComparatorInfo `json:"bodyComparator,omitempty"`
+	}{}
+	if probe.Data == "" {
+		return nil
+	}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &data); err != nil {
+		return nil
+	}
+	return data.BodyComparator
+}
+
 // getHTTPBody fetch the http body for the post request
 // It will use body or bodyPath attributes to get the http request body
 // if both are provided, it will use body field
@@ -208,49 +262,10 @@ func getHTTPBody(httpBody v1alpha1.PostMethod, probeName string) (string, error)
 	return out.String(), nil
 }
 
-// triggerContinuousHTTPProbe trigger the continuous http probes
-func triggerContinuousHTTPProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-	var isExperimentFailed bool
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-	}
-
-	// it triggers the http probe for the entire duration of chaos and it fails, if any error encounter
-	// it marked the error for the probes, if any
-loop:
-	for {
-		err = triggerHTTPProbe(probe, chaosresult)
-		// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-		if err != nil {
-			err = addProbePhase(err, string(chaosDetails.Phase))
-			for index := range chaosresult.ProbeDetails {
-				if chaosresult.ProbeDetails[index].Name == probe.Name {
-					chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-					chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-					log.Errorf("The %v http probe has been Failed, err: %v", probe.Name, err)
-					isExperimentFailed = true
-					break loop
-				}
-			}
-		}
-		// waiting for the probe polling interval
-		time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("Unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
-}
-
 // preChaosHTTPProbe trigger the http probe for prechaos phase
 func preChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
 
+	var err error
 	switch probe.Mode {
 	case "SOT", "Edge":
 
@@ -269,7 +284,7 @@ func preChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
 		}
 		// trigger the http probe
-		err = triggerHTTPProbe(probe, resultDetails)
+		err = triggerHTTPProbe(context.Background(), probe, resultDetails)
 
 		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 		// it will update the status of all the unrun probes as well
@@ -286,7 +301,9 @@ func preChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 			"Mode":           probe.Mode,
 			"Phase":          "PreChaos",
 		})
-		go triggerContinuousHTTPProbe(probe, clients, resultDetails, chaosDetails)
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, -1, func(ctx context.Context) error {
+			return triggerHTTPProbe(ctx, probe, resultDetails)
+		})
 	}
 	return nil
 }
@@ -294,6 +311,7 @@ func preChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 // postChaosHTTPProbe trigger the http probe for postchaos phase
 func postChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
 
+	var err error
 	switch probe.Mode {
 	case "EOT", "Edge":
 
@@ -313,7 +331,7 @@ func postChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Res
 		}
 
 		// trigger the http probe
-		err = triggerHTTPProbe(probe, resultDetails)
+		err = triggerHTTPProbe(context.Background(), probe, resultDetails)
 
 		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 		// it will update the status of all the unrun probes as well
@@ -331,58 +349,6 @@ func postChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Res
 	return nil
 }
 
-// triggerOnChaosHTTPProbe trigger the onchaos http probes
-func triggerOnChaosHTTPProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-
-	var isExperimentFailed bool
-	duration := chaosDetails.ChaosDuration
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-		duration = math.Maximum(0, duration-probe.RunProperties.InitialDelaySeconds)
-	}
-
-	endTime := time.After(time.Duration(duration) * time.Second)
-
-	// it trigger the http probe for the entire duration of chaos and it fails, if any error encounter
-	// it marked the error for the probes, if any
-loop:
-	for {
-		select {
-		case <-endTime:
-			log.Infof("[Chaos]: Time is up for the %v probe", probe.Name)
-			endTime = nil
-			break loop
-		default:
-			err = triggerHTTPProbe(probe, chaosresult)
-			// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-			if err != nil {
-				err = addProbePhase(err, string(chaosDetails.Phase))
-				for index := range chaosresult.ProbeDetails {
-					if chaosresult.ProbeDetails[index].Name == probe.Name {
-						chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-						chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-						isExperimentFailed = true
-						break loop
-					}
-				}
-			}
-
-			// waiting for the probe polling interval
-			time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-		}
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
-}
-
 // onChaosHTTPProbe trigger the http probe for DuringChaos phase
 func onChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) {
 
@@ -397,7 +363,9 @@ func onChaosHTTPProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			"Mode":           probe.Mode,
 			"Phase":          "DuringChaos",
 		})
-		go triggerOnChaosHTTPProbe(probe, clients, resultDetails, chaosDetails)
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration, func(ctx context.Context) error {
+			return triggerHTTPProbe(ctx, probe, resultDetails)
+		})
 	}
 
 }
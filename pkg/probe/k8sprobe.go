@@ -1,6 +1,7 @@
 package probe
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"strings"
@@ -12,7 +13,6 @@ import (
 	"github.com/litmuschaos/litmus-go/pkg/cerrors"
 	"github.com/litmuschaos/litmus-go/pkg/clients"
 	"github.com/litmuschaos/litmus-go/pkg/log"
-	"github.com/litmuschaos/litmus-go/pkg/math"
 	"github.com/litmuschaos/litmus-go/pkg/types"
 	"github.com/litmuschaos/litmus-go/pkg/utils/retry"
 	"github.com/sirupsen/logrus"
@@ -20,6 +20,8 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+	"k8s.io/client-go/util/jsonpath"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 // prepareK8sProbe contains the steps to prepare the k8s probe
@@ -43,8 +45,9 @@ func prepareK8sProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Result
 }
 
 // triggerK8sProbe run the k8s probe command
-func triggerK8sProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, resultDetails *types.ResultDetails) error {
+func triggerK8sProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, clients clients.ClientSets, resultDetails *types.ResultDetails) error {
 
+	var err error
 	inputs := probe.K8sProbeInputs
 
 	// It parses the templated command and return normal string
@@ -78,6 +81,7 @@ func triggerK8sProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets,
 	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
 	// for a timeout, it will run the command, if it fails wait for the interval and again execute the command until timeout expires
 	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
 		Timeout(int64(probe.RunProperties.ProbeTimeout)).
 		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
 		TryWithTimeout(func(attempt uint) error {
@@ -109,6 +113,12 @@ func triggerK8sProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets,
 					log.Errorf("the %v k8s probe has Failed, err: %v", probe.Name, err)
 					return err
 				}
+			case "compare":
+				rc := getAndIncrementRunCount(resultDetails, probe.Name)
+				if err = compareResourceFields(probe, gvr, parsedResourceNames, clients, resultDetails, rc); err != nil {
+					log.Errorf("the %v k8s probe has Failed, err: %v", probe.Name, err)
+					return err
+				}
 			default:
 				return cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("operation type '%s' not supported in the k8s probe", inputs.Operation)}
 			}
@@ -122,62 +132,23 @@ func triggerK8sProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets,
 	return nil
 }
 
-// triggerContinuousK8sProbe trigger the continuous k8s probes
-func triggerContinuousK8sProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-	var isExperimentFailed bool
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-	}
-
-	// it triggers the k8s probe for the entire duration of chaos and it fails, if any error encounter
-	// marked the error for the probes, if any
-loop:
-	for {
-		err = triggerK8sProbe(probe, clients, chaosresult)
-		// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-		if err != nil {
-			err = addProbePhase(err, string(chaosDetails.Phase))
-			for index := range chaosresult.ProbeDetails {
-				if chaosresult.ProbeDetails[index].Name == probe.Name {
-					chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-					chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-					log.Errorf("the %v k8s probe has been Failed, err: %v", probe.Name, err)
-					isExperimentFailed = true
-					break loop
-				}
-			}
-		}
-		// waiting for the probe polling interval
-		time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
-}
-
 // createResource creates the resource from the data provided inside data field
 func createResource(probe v1alpha1.ProbeAttributes, gvr schema.GroupVersionResource, clients clients.ClientSets) error {
 	decUnstructured := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
 	// Decode YAML manifest into unstructured.Unstructured
 	data := &unstructured.Unstructured{}
-	_, _, err = decUnstructured.Decode([]byte(probe.Data), nil, data)
+	_, _, err := decUnstructured.Decode([]byte(probe.Data), nil, data)
 	if err != nil {
 		return cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
 	}
-	_, err := clients.DynamicClient.Resource(gvr).Namespace(probe.K8sProbeInputs.Namespace).Create(context.Background(), data, v1.CreateOptions{})
+	_, err = clients.DynamicClient.Resource(gvr).Namespace(probe.K8sProbeInputs.Namespace).Create(context.Background(), data, v1.CreateOptions{})
 
 	return err
 }
 
 // deleteResource deletes the resource with matching label & field selector
 func deleteResource(probe v1alpha1.ProbeAttributes, gvr schema.GroupVersionResource, parsedResourceNames []string, clients clients.ClientSets) error {
+	var err error
 	// resource name has higher priority
 	if len(parsedResourceNames) > 0 {
 		// check if all resources are available
@@ -244,6 +215,107 @@ func areResourcesWithNamePresent(probe v1alpha1.ProbeAttributes, gvr schema.Grou
 	return nil
 }
 
+// k8sCompareInputs contains the inputs for the compare operation of the k8s probe
+// it is provided inside the data field of the probe, in yaml/json format
+type k8sCompareInputs struct {
+	// FieldPath is the jsonpath of the field, which needs to be compared
+	FieldPath string `json:"fieldPath,omitempty"`
+	// Comparator check for the correctness of the extracted field
+	Comparator v1alpha1.ComparatorInfo `json:"comparator,omitempty"`
+	// Match defines the evaluation across all the matched resources
+	// it can be all or any, defaults to all
+	Match string `json:"match,omitempty"`
+}
+
+// compareResourceFields extracts the field from all the matching resources and compare it with the expected value
+// it passes if all(or any, based on match) of the resources follows the comparator criteria
+// the baseline criteria compares the field of each resource relative to its own baseline value
+func compareResourceFields(probe v1alpha1.ProbeAttributes, gvr schema.GroupVersionResource, parsedResourceNames []string, clients clients.ClientSets, resultDetails *types.ResultDetails, rc int) error {
+	inputs := k8sCompareInputs{}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &inputs); err != nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to parse the compare inputs from data, err: %v", err)}
+	}
+	if inputs.FieldPath == "" {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: "fieldPath is required for the compare operation"}
+	}
+
+	resources, err := getResources(probe, gvr, parsedResourceNames, clients)
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, res := range resources {
+		value, err := extractFieldValue(res.UnstructuredContent(), inputs.FieldPath)
+		if err != nil {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to extract '%s' field from '%s' resource, err: %v", inputs.FieldPath, res.GetName(), err)}
+		}
+		if _, err := validateResult(inputs.Comparator, probe.Name, fmt.Sprintf("%s/%s", res.GetNamespace(), res.GetName()), value, rc, resultDetails, cerrors.ErrorTypeK8sProbe); err != nil {
+			failures = append(failures, fmt.Sprintf("{resource: %s, reason: %s}", res.GetName(), getDescription(err)))
+			continue
+		}
+		if strings.ToLower(inputs.Match) == "any" {
+			return nil
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	if strings.ToLower(inputs.Match) == "any" {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("none of the resources matched the comparator criteria: [%s]", strings.Join(failures, ","))}
+	}
+	return cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("%v out of %v resources didn't match the comparator criteria: [%s]", len(failures), len(resources), strings.Join(failures, ","))}
+}
+
+// getResources returns the resources with matching names or label & field selectors
+func getResources(probe v1alpha1.ProbeAttributes, gvr schema.GroupVersionResource, parsedResourceNames []string, clients clients.ClientSets) ([]unstructured.Unstructured, error) {
+	var resources []unstructured.Unstructured
+
+	// resource name has higher priority
+	if len(parsedResourceNames) > 0 {
+		for _, res := range parsedResourceNames {
+			resource, err := clients.DynamicClient.Resource(gvr).Namespace(probe.K8sProbeInputs.Namespace).Get(context.Background(), res, v1.GetOptions{})
+			if err != nil {
+				return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to get the resources with name %v, err: %v", res, err)}
+			}
+			resources = append(resources, *resource)
+		}
+		return resources, nil
+	}
+
+	resourceList, err := clients.DynamicClient.Resource(gvr).Namespace(probe.K8sProbeInputs.Namespace).List(context.Background(), v1.ListOptions{
+		FieldSelector: probe.K8sProbeInputs.FieldSelector,
+		LabelSelector: probe.K8sProbeInputs.LabelSelector,
+	})
+	if err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to list the resources with matching selector, err: %v", err)}
+	} else if len(resourceList.Items) == 0 {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeK8sProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("no resource found with provided {labelSelectors: %s, fieldSelectors: %s} selectors", probe.K8sProbeInputs.LabelSelector, probe.K8sProbeInputs.FieldSelector)}
+	}
+	return resourceList.Items, nil
+}
+
+// extractFieldValue extracts the value of the given jsonpath from the resource
+// the missing fields are evaluated as empty string
+func extractFieldValue(obj interface{}, fieldPath string) (string, error) {
+	fieldPath = strings.TrimSpace(fieldPath)
+	if !strings.HasPrefix(fieldPath, "{") {
+		fieldPath = "{" + fieldPath + "}"
+	}
+
+	j := jsonpath.New("fieldPath").AllowMissingKeys(true)
+	if err := j.Parse(fieldPath); err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := j.Execute(&out, obj); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
 func resourcesAbsent(probe v1alpha1.ProbeAttributes, gvr schema.GroupVersionResource, parsedResourceNames []string, clients clients.ClientSets) error {
 	// resource name has higher priority
 	if len(parsedResourceNames) > 0 {
@@ -277,6 +349,7 @@ func resourcesAbsent(probe v1alpha1.ProbeAttributes, gvr schema.GroupVersionReso
 // preChaosK8sProbe trigger the k8s probe for prechaos phase
 func preChaosK8sProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
 
+	var err error
 	switch strings.ToLower(probe.Mode) {
 	case "sot", "edge":
 
@@ -294,7 +367,7 @@ func preChaosK8sProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
 		}
 		// triggering the k8s probe
-		err = triggerK8sProbe(probe, clients, resultDetails)
+		err = triggerK8sProbe(context.Background(), probe, clients, resultDetails)
 
 		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 		// it will update the status of all the unrun probes as well
@@ -311,7 +384,9 @@ func preChaosK8sProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			"Mode":           probe.Mode,
 			"Phase":          "PreChaos",
 		})
-		go triggerContinuousK8sProbe(probe, clients, resultDetails, chaosDetails)
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, -1, func(ctx context.Context) error {
+			return triggerK8sProbe(ctx, probe, clients, resultDetails)
+		})
 	}
 	return nil
 }
@@ -319,6 +394,7 @@ func preChaosK8sProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 // postChaosK8sProbe trigger the k8s probe for postchaos phase
 func postChaosK8sProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
 
+	var err error
 	switch strings.ToLower(probe.Mode) {
 	case "eot", "edge":
 
@@ -336,7 +412,7 @@ func postChaosK8sProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
 		}
 		// triggering the k8s probe
-		err = triggerK8sProbe(probe, clients, resultDetails)
+		err = triggerK8sProbe(context.Background(), probe, clients, resultDetails)
 
 		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 		// it will update the status of all the unrun probes as well
@@ -368,58 +444,9 @@ func onChaosK8sProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Result
 			"Mode":           probe.Mode,
 			"Phase":          "DuringChaos",
 		})
-		go triggerOnChaosK8sProbe(probe, clients, resultDetails, chaosDetails)
-	}
-
-}
-
-// triggerOnChaosK8sProbe trigger the onchaos k8s probes
-func triggerOnChaosK8sProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-
-	var isExperimentFailed bool
-	duration := chaosDetails.ChaosDuration
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-		duration = math.Maximum(0, duration-probe.RunProperties.InitialDelaySeconds)
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration, func(ctx context.Context) error {
+			return triggerK8sProbe(ctx, probe, clients, resultDetails)
+		})
 	}
 
-	endTime := time.After(time.Duration(duration) * time.Second)
-
-	// it triggers the k8s probe for the entire duration of chaos and it fails, if any error encounter
-	// marked the error for the probes, if any
-loop:
-	for {
-		select {
-		case <-endTime:
-			log.Infof("[Chaos]: Time is up for the %v probe", probe.Name)
-			break loop
-		default:
-			err = triggerK8sProbe(probe, clients, chaosresult)
-			// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-			if err != nil {
-				err = addProbePhase(err, string(chaosDetails.Phase))
-				for index := range chaosresult.ProbeDetails {
-					if chaosresult.ProbeDetails[index].Name == probe.Name {
-						chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-						chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-						log.Errorf("The %v k8s probe has been Failed, err: %v", probe.Name, err)
-						isExperimentFailed = true
-						break loop
-					}
-				}
-			}
-			// waiting for the probe polling interval
-			time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-		}
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
 }
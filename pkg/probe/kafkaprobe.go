@@ -0,0 +1,551 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	cmp "github.com/litmuschaos/litmus-go/pkg/probe/comparator"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/retry"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"github.com/sirupsen/logrus"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// kafkaRoundTripOutput and kafkaConsumerLagOutput are the outputs of the kafka probe, which contain the round trip latency and the consumer lag
+	kafkaRoundTripOutput   = "roundTripLatency"
+	kafkaConsumerLagOutput = "consumerLag"
+)
+
+// kafkaProbeInputs contains the inputs required for the kafka probe
+// it is provided inside the data field of the probe, in yaml/json format
+type kafkaProbeInputs struct {
+	// Brokers contains the addresses of the kafka brokers, in host:port format
+	Brokers []string `json:"brokers,omitempty"`
+	// TLS enables the tls connection to the brokers
+	TLS *kafkaTLS `json:"tls,omitempty"`
+	// SASL enables the sasl authentication with the brokers
+	SASL *kafkaSASL `json:"sasl,omitempty"`
+	// RoundTrip produces a message on the test topic and consumes it back
+	RoundTrip *kafkaRoundTrip `json:"roundTrip,omitempty"`
+	// ConsumerLag checks the lag of the consumer group
+	ConsumerLag *kafkaConsumerLag `json:"consumerLag,omitempty"`
+}
+
+// kafkaTLS contains the tls configuration for the kafka connection
+type kafkaTLS struct {
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// kafkaSASL contains the sasl configuration for the kafka connection
+type kafkaSASL struct {
+	// Mechanism of the sasl authentication, supports: plain, scram-sha-256, scram-sha-512
+	Mechanism   string           `json:"mechanism,omitempty"`
+	Credentials probeCredentials `json:"credentials,omitempty"`
+}
+
+// kafkaRoundTrip contains the inputs for the produce/consume check
+type kafkaRoundTrip struct {
+	// Topic used to produce and consume the test message
+	Topic string `json:"topic,omitempty"`
+	// Partition of the topic, defaults to 0
+	Partition int `json:"partition,omitempty"`
+	// CreateTopic creates the topic, if it doesn't exist
+	CreateTopic bool `json:"createTopic,omitempty"`
+	// ReplicationFactor of the created topic, defaults to 1
+	ReplicationFactor int `json:"replicationFactor,omitempty"`
+	// MaxLatency is the maximum allowed end-to-end latency, e.g. 500ms
+	MaxLatency string `json:"maxLatency,omitempty"`
+}
+
+// kafkaConsumerLag contains the inputs for the consumer group lag check
+type kafkaConsumerLag struct {
+	Group string `json:"group,omitempty"`
+	// Topics consumed by the group, the lag is aggregated across the partitions of these topics
+	Topics []string `json:"topics,omitempty"`
+	// Aggregation of the lag across the partitions, supports: sum, max. Defaults to sum
+	Aggregation string `json:"aggregation,omitempty"`
+	// Comparator check for the aggregated lag, the type defaults to int
+	Comparator v1alpha1.ComparatorInfo `json:"comparator,omitempty"`
+}
+
+// prepareKafkaProbe contains the steps to prepare the kafka probe
+// kafka probe produces/consumes a message on the test topic and checks the lag of the consumer group
+func prepareKafkaProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, phase string) error {
+
+	switch strings.ToLower(phase) {
+	case "prechaos":
+		if err := preChaosKafkaProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "postchaos":
+		if err := postChaosKafkaProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "duringchaos":
+		onChaosKafkaProbe(probe, resultDetails, clients, chaosDetails)
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("phase '%s' not supported in the kafka probe", phase)}
+	}
+	return nil
+}
+
+// preChaosKafkaProbe trigger the kafka probe for prechaos phase
+func preChaosKafkaProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "sot", "edge":
+
+		//DISPLAY THE KAFKA PROBE INFO
+		log.InfoWithValues("[Probe]: The kafka probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// triggering the kafka probe
+		err := triggerKafkaProbe(context.Background(), probe, clients, resultDetails, chaosDetails)
+
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PreChaos"); err != nil {
+			return err
+		}
+	case "continuous":
+
+		//DISPLAY THE KAFKA PROBE INFO
+		log.InfoWithValues("[Probe]: The kafka probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, -1, func(ctx context.Context) error {
+			return triggerKafkaProbe(ctx, probe, clients, resultDetails, chaosDetails)
+		})
+	}
+	return nil
+}
+
+// postChaosKafkaProbe trigger the kafka probe for postchaos phase
+func postChaosKafkaProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "eot", "edge":
+
+		//DISPLAY THE KAFKA PROBE INFO
+		log.InfoWithValues("[Probe]: The kafka probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PostChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// triggering the kafka probe
+		err := triggerKafkaProbe(context.Background(), probe, clients, resultDetails, chaosDetails)
+
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	case "continuous", "onchaos":
+		// it will check for the error, It will detect the error if any error encountered in probe during chaos
+		err := checkForErrorInContinuousProbe(resultDetails, probe.Name)
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onChaosKafkaProbe trigger the kafka probe for DuringChaos phase
+func onChaosKafkaProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) {
+
+	switch strings.ToLower(probe.Mode) {
+	case "onchaos":
+
+		//DISPLAY THE KAFKA PROBE INFO
+		log.InfoWithValues("[Probe]: The kafka probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "DuringChaos",
+		})
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration, func(ctx context.Context) error {
+			return triggerKafkaProbe(ctx, probe, clients, resultDetails, chaosDetails)
+		})
+	}
+}
+
+// triggerKafkaProbe runs the round trip and consumer lag checks against the kafka brokers
+func triggerKafkaProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, clients clients.ClientSets, resultDetails *types.ResultDetails, chaosDetails *types.ChaosDetails) error {
+	inputs, err := getKafkaProbeInputs(probe)
+	if err != nil {
+		return err
+	}
+	client, err := getKafkaClient(probe.Name, inputs, clients, chaosDetails)
+	if err != nil {
+		return err
+	}
+	defer client.Transport.(*kafka.Transport).CloseIdleConnections()
+
+	var description string
+	// it will retry for some retry count, in each iteration of try it contains following things
+	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
+	// for a timeout, it will run the checks, if it fails wait for the interval and again run the checks until timeout expires
+	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
+		Timeout(int64(probe.RunProperties.ProbeTimeout)).
+		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
+		TryWithTimeout(func(attempt uint) error {
+			ctx, cancel := context.WithTimeout(ctx, time.Duration(probe.RunProperties.ProbeTimeout)*time.Millisecond)
+			defer cancel()
+
+			rc := getAndIncrementRunCount(resultDetails, probe.Name)
+			var descriptions []string
+			// the results of the checks are stored under the distinct keys, as both the checks can be provided
+			results := map[string]string{}
+
+			if inputs.RoundTrip != nil {
+				latency, err := runKafkaRoundTrip(ctx, client, probe.Name, *inputs.RoundTrip)
+				if err != nil {
+					return cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
+				}
+				if inputs.RoundTrip.MaxLatency != "" {
+					if err := cmp.RunCount(rc).
+						FirstValue(latency.String()).
+						SecondValue(inputs.RoundTrip.MaxLatency).
+						Criteria("<=").
+						ProbeName(probe.Name).
+						CompareDuration(cerrors.ErrorTypeKafkaProbe); err != nil {
+						log.Errorf("The %v kafka probe has been Failed, err: %v", probe.Name, err)
+						return cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("round trip latency check failed, %s", getDescription(err))}
+					}
+				}
+				descriptions = append(descriptions, fmt.Sprintf("Message is produced and consumed on the %v topic in '%v'", inputs.RoundTrip.Topic, latency))
+				results[kafkaRoundTripOutput] = latency.String()
+			}
+
+			if inputs.ConsumerLag != nil {
+				lag, err := getConsumerGroupLag(ctx, client, *inputs.ConsumerLag)
+				if err != nil {
+					return cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
+				}
+				if _, err := validateResult(inputs.ConsumerLag.Comparator, probe.Name, kafkaConsumerLagOutput, strconv.FormatInt(lag, 10), rc, resultDetails, cerrors.ErrorTypeKafkaProbe); err != nil {
+					log.Errorf("The %v kafka probe has been Failed, err: %v", probe.Name, err)
+					return cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("consumer lag check failed, %s", getDescription(err))}
+				}
+				descriptions = append(descriptions, fmt.Sprintf("Lag of the %v consumer group is '%v'", inputs.ConsumerLag.Group, lag))
+				results[kafkaConsumerLagOutput] = strconv.FormatInt(lag, 10)
+			}
+
+			description = strings.Join(descriptions, ". ")
+			// storing the results as json response, the named outputs can extract them via the jsonPath
+			response, err := json.Marshal(results)
+			if err != nil {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to marshal the results, err: %v", err)}
+			}
+			if err := registerProbeArtifacts(probe, resultDetails, string(response), cerrors.ErrorTypeKafkaProbe); err != nil {
+				return err
+			}
+			registerDefaultOutputs(probe.Name, resultDetails, results)
+			return nil
+		}); err != nil {
+		return err
+	}
+	setProbeDescription(resultDetails, probe, description)
+	return nil
+}
+
+// runKafkaRoundTrip produces a message on the test topic and fetches it back
+// it returns the end-to-end latency, from the produce request till the message is fetched
+func runKafkaRoundTrip(ctx context.Context, client *kafka.Client, probeName string, inputs kafkaRoundTrip) (time.Duration, error) {
+	if inputs.CreateTopic {
+		if err := createKafkaTopic(ctx, client, inputs); err != nil {
+			return 0, err
+		}
+	}
+
+	key := fmt.Sprintf("%s-%d", probeName, time.Now().UnixNano())
+	startTime := time.Now()
+	produceResp, err := client.Produce(ctx, &kafka.ProduceRequest{
+		Topic:        inputs.Topic,
+		Partition:    inputs.Partition,
+		RequiredAcks: kafka.RequireAll,
+		Records:      kafka.NewRecordReader(kafka.Record{Key: kafka.NewBytes([]byte(key)), Value: kafka.NewBytes([]byte("litmus probe message"))}),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to produce the message on the %v topic, err: %v", inputs.Topic, err)
+	}
+	if produceResp.Error != nil {
+		return 0, fmt.Errorf("unable to produce the message on the %v topic, err: %v", inputs.Topic, produceResp.Error)
+	}
+
+	// fetching the records from the produced offset, till the produced message is found
+	offset := produceResp.BaseOffset
+	for {
+		fetchResp, err := client.Fetch(ctx, &kafka.FetchRequest{
+			Topic:     inputs.Topic,
+			Partition: inputs.Partition,
+			Offset:    offset,
+			MinBytes:  1,
+			MaxBytes:  1024 * 1024,
+			MaxWait:   500 * time.Millisecond,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("unable to consume the message from the %v topic, err: %v", inputs.Topic, err)
+		}
+		if fetchResp.Error != nil {
+			return 0, fmt.Errorf("unable to consume the message from the %v topic, err: %v", inputs.Topic, fetchResp.Error)
+		}
+		found, next, err := findKafkaRecord(fetchResp.Records, key, offset)
+		if err != nil {
+			return 0, fmt.Errorf("unable to read the messages of the %v topic, err: %v", inputs.Topic, err)
+		}
+		if found {
+			return time.Since(startTime), nil
+		}
+		offset = next
+		if ctx.Err() != nil {
+			return 0, fmt.Errorf("produced message is not consumed from the %v topic within the probe timeout", inputs.Topic)
+		}
+	}
+}
+
+// findKafkaRecord checks whether the record with the given key is present in the fetched records
+// it returns the offset to fetch the next records from
+func findKafkaRecord(records kafka.RecordReader, key string, offset int64) (bool, int64, error) {
+	if records == nil {
+		return false, offset, nil
+	}
+	for {
+		record, err := records.ReadRecord()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return false, offset, nil
+			}
+			return false, offset, err
+		}
+		// the fetched batches may start before the requested offset
+		if record.Offset < offset {
+			continue
+		}
+		offset = record.Offset + 1
+		if record.Key == nil {
+			continue
+		}
+		recordKey, err := kafka.ReadAll(record.Key)
+		if err != nil {
+			return false, offset, err
+		}
+		if string(recordKey) == key {
+			return true, offset, nil
+		}
+	}
+}
+
+// createKafkaTopic creates the test topic with a single partition, if it doesn't exist
+func createKafkaTopic(ctx context.Context, client *kafka.Client, inputs kafkaRoundTrip) error {
+	resp, err := client.CreateTopics(ctx, &kafka.CreateTopicsRequest{
+		Topics: []kafka.TopicConfig{{
+			Topic:             inputs.Topic,
+			NumPartitions:     inputs.Partition + 1,
+			ReplicationFactor: inputs.ReplicationFactor,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create the %v topic, err: %v", inputs.Topic, err)
+	}
+	if err := resp.Errors[inputs.Topic]; err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return fmt.Errorf("unable to create the %v topic, err: %v", inputs.Topic, err)
+	}
+	return nil
+}
+
+// getConsumerGroupLag returns the lag of the consumer group, aggregated across the partitions of the topics
+// the lag of a partition is the difference between the last offset and the committed offset of the group
+func getConsumerGroupLag(ctx context.Context, client *kafka.Client, inputs kafkaConsumerLag) (int64, error) {
+	// the metadata request without the topics returns all the topics of the cluster, which aren't consumed by the group
+	if len(inputs.Topics) == 0 {
+		return 0, fmt.Errorf("topics of the %v consumer group are not provided", inputs.Group)
+	}
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: inputs.Topics})
+	if err != nil {
+		return 0, fmt.Errorf("unable to get the metadata of the %v topics, err: %v", inputs.Topics, err)
+	}
+
+	partitions := map[string][]int{}
+	offsetRequests := map[string][]kafka.OffsetRequest{}
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			return 0, fmt.Errorf("unable to get the metadata of the %v topic, err: %v", topic.Name, topic.Error)
+		}
+		for _, partition := range topic.Partitions {
+			partitions[topic.Name] = append(partitions[topic.Name], partition.ID)
+			offsetRequests[topic.Name] = append(offsetRequests[topic.Name], kafka.FirstOffsetOf(partition.ID), kafka.LastOffsetOf(partition.ID))
+		}
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: inputs.Group, Topics: partitions})
+	if err != nil {
+		return 0, fmt.Errorf("unable to get the offsets of the %v consumer group, err: %v", inputs.Group, err)
+	}
+	if committed.Error != nil {
+		return 0, fmt.Errorf("unable to get the offsets of the %v consumer group, err: %v", inputs.Group, committed.Error)
+	}
+	offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: offsetRequests})
+	if err != nil {
+		return 0, fmt.Errorf("unable to get the offsets of the %v topics, err: %v", inputs.Topics, err)
+	}
+
+	var result int64
+	for topic, topicOffsets := range offsets.Topics {
+		for _, partitionOffsets := range topicOffsets {
+			if partitionOffsets.Error != nil {
+				return 0, fmt.Errorf("unable to get the offsets of the %v partition of %v topic, err: %v", partitionOffsets.Partition, topic, partitionOffsets.Error)
+			}
+			// the whole partition is considered as lag, if the group hasn't committed any offset yet
+			lag := partitionOffsets.LastOffset - partitionOffsets.FirstOffset
+			for _, partition := range committed.Topics[topic] {
+				if partition.Partition == partitionOffsets.Partition && partition.CommittedOffset >= 0 {
+					lag = partitionOffsets.LastOffset - partition.CommittedOffset
+				}
+			}
+			if lag < 0 {
+				lag = 0
+			}
+
+			switch inputs.Aggregation {
+			case "max":
+				if lag > result {
+					result = lag
+				}
+			default:
+				result += lag
+			}
+		}
+	}
+	return result, nil
+}
+
+// getKafkaClient returns the kafka client for the given brokers along with the tls and sasl configurations
+func getKafkaClient(probeName string, inputs kafkaProbeInputs, clients clients.ClientSets, chaosDetails *types.ChaosDetails) (*kafka.Client, error) {
+	transport := &kafka.Transport{}
+	if inputs.TLS != nil {
+		transport.TLS = &tls.Config{InsecureSkipVerify: inputs.TLS.InsecureSkipVerify}
+	}
+	if inputs.SASL != nil {
+		username, password, err := getProbeCredentials(probeName, inputs.SASL.Credentials, clients, chaosDetails.ChaosNamespace, cerrors.ErrorTypeKafkaProbe)
+		if err != nil {
+			return nil, err
+		}
+		var mechanism sasl.Mechanism
+		switch strings.ToLower(inputs.SASL.Mechanism) {
+		case "", "plain":
+			mechanism = plain.Mechanism{Username: username, Password: password}
+		case "scram-sha-256":
+			mechanism, err = scram.Mechanism(scram.SHA256, username, password)
+		case "scram-sha-512":
+			mechanism, err = scram.Mechanism(scram.SHA512, username, password)
+		default:
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("sasl mechanism '%s' not supported in the kafka probe", inputs.SASL.Mechanism)}
+		}
+		if err != nil {
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("unable to create the sasl mechanism, err: %v", err)}
+		}
+		transport.SASL = mechanism
+	}
+	return &kafka.Client{Addr: kafka.TCP(inputs.Brokers...), Transport: transport}, nil
+}
+
+// getKafkaProbeInputs parse the kafka probe inputs from the data field of the probe
+func getKafkaProbeInputs(probe v1alpha1.ProbeAttributes) (kafkaProbeInputs, error) {
+	inputs := kafkaProbeInputs{}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &inputs); err != nil {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to parse the kafka probe inputs from data, err: %v", err)}
+	}
+
+	var reason string
+	switch {
+	case len(inputs.Brokers) == 0:
+		reason = "brokers are required for the kafka probe"
+	case inputs.RoundTrip == nil && inputs.ConsumerLag == nil:
+		reason = "either roundTrip or consumerLag check is required for the kafka probe"
+	case inputs.RoundTrip != nil && inputs.RoundTrip.Topic == "":
+		reason = "topic is required for the roundTrip check"
+	case inputs.ConsumerLag != nil && (inputs.ConsumerLag.Group == "" || len(trimTopics(inputs.ConsumerLag.Topics)) == 0):
+		reason = "group and topics are required for the consumerLag check"
+	case inputs.ConsumerLag != nil && inputs.ConsumerLag.Comparator.Criteria == "":
+		reason = "comparator criteria is required for the consumerLag check"
+	}
+	if reason != "" {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: reason}
+	}
+
+	// setting the defaults for the optional inputs
+	if inputs.RoundTrip != nil && inputs.RoundTrip.ReplicationFactor == 0 {
+		inputs.RoundTrip.ReplicationFactor = 1
+	}
+	if inputs.ConsumerLag != nil {
+		// the blank topics are dropped, the empty topics would fetch the metadata of all the topics of the cluster
+		inputs.ConsumerLag.Topics = trimTopics(inputs.ConsumerLag.Topics)
+		inputs.ConsumerLag.Aggregation = strings.ToLower(inputs.ConsumerLag.Aggregation)
+		if inputs.ConsumerLag.Aggregation == "" {
+			inputs.ConsumerLag.Aggregation = "sum"
+		}
+		if inputs.ConsumerLag.Aggregation != "sum" && inputs.ConsumerLag.Aggregation != "max" {
+			return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeKafkaProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("aggregation '%s' not supported in the kafka probe", inputs.ConsumerLag.Aggregation)}
+		}
+		if inputs.ConsumerLag.Comparator.Type == "" {
+			inputs.ConsumerLag.Comparator.Type = "int"
+		}
+	}
+	if inputs.SASL != nil {
+		if inputs.SASL.Credentials.UsernameKey == "" {
+			inputs.SASL.Credentials.UsernameKey = "username"
+		}
+		if inputs.SASL.Credentials.PasswordKey == "" {
+			inputs.SASL.Credentials.PasswordKey = "password"
+		}
+	}
+	return inputs, nil
+}
+
+// trimTopics returns the topics after trimming the spaces, the blank topics are dropped
+func trimTopics(topics []string) []string {
+	var trimmed []string
+	for _, topic := range topics {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			trimmed = append(trimmed, topic)
+		}
+	}
+	return trimmed
+}
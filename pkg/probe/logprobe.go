@@ -0,0 +1,477 @@
+package probe
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/math"
+	cmp "github.com/litmuschaos/litmus-go/pkg/probe/comparator"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientTypes "k8s.io/apimachinery/pkg/types"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// logResyncInterval is the interval to re-list the target pods of the log probe
+const logResyncInterval = 5 * time.Second
+
+// logProbeInputs contains the inputs required for the log probe
+// it is provided inside the data field of the probe, in yaml/json format
+type logProbeInputs struct {
+	// Namespace of the target pods
+	Namespace string `json:"namespace,omitempty"`
+	// LabelSelector to select the target pods
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// Container whose logs need to be streamed
+	// it uses the first container of the pod, if not provided
+	Container string `json:"container,omitempty"`
+	// Pattern is the regex, which is searched inside the log lines
+	Pattern string `json:"pattern,omitempty"`
+	// Comparator check for the count of matching log lines
+	// it supports int comparator, default criteria is '>=' with value '1'
+	Comparator v1alpha1.ComparatorInfo `json:"comparator,omitempty"`
+}
+
+// logCounter counts the log lines matching the pattern
+type logCounter struct {
+	mu        sync.Mutex
+	count     int
+	lastMatch string
+}
+
+// continuousLogCounters contains the counters of the continuous log probes
+// these are evaluated in the postchaos phase
+var continuousLogCounters = struct {
+	sync.Mutex
+	counters map[string]*logCounter
+	errors   map[string]error
+}{counters: map[string]*logCounter{}, errors: map[string]error{}}
+
+// prepareLogProbe contains the steps to prepare the log probe
+// log probe streams the logs of the target pods and counts the log lines matching the given pattern
+func prepareLogProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, phase string) error {
+
+	switch strings.ToLower(phase) {
+	case "prechaos":
+		if err := preChaosLogProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "postchaos":
+		if err := postChaosLogProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "duringchaos":
+		onChaosLogProbe(probe, resultDetails, clients, chaosDetails)
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("phase '%s' not supported in the log probe", phase)}
+	}
+	return nil
+}
+
+// preChaosLogProbe trigger the log probe for prechaos phase
+func preChaosLogProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "sot", "edge":
+
+		//DISPLAY THE LOG PROBE INFO
+		log.InfoWithValues("[Probe]: The log probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// streaming the logs for the probe timeout window
+		err := triggerLogProbe(context.Background(), probe, clients, resultDetails, time.Duration(probe.RunProperties.ProbeTimeout)*time.Millisecond)
+
+		// failing the probe, if the matching log lines doesn't follow the comparator criteria
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PreChaos"); err != nil {
+			return err
+		}
+	case "continuous":
+
+		//DISPLAY THE LOG PROBE INFO
+		log.InfoWithValues("[Probe]: The log probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		scheduleStream(chaosDetails, func(ctx context.Context) {
+			triggerContinuousLogProbe(ctx, probe, clients)
+		})
+	}
+	return nil
+}
+
+// postChaosLogProbe trigger the log probe for postchaos phase
+func postChaosLogProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "eot", "edge":
+
+		//DISPLAY THE LOG PROBE INFO
+		log.InfoWithValues("[Probe]: The log probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PostChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// streaming the logs for the probe timeout window
+		err := triggerLogProbe(context.Background(), probe, clients, resultDetails, time.Duration(probe.RunProperties.ProbeTimeout)*time.Millisecond)
+
+		// failing the probe, if the matching log lines doesn't follow the comparator criteria
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	case "continuous":
+		// evaluating the log lines, matched during the entire chaos duration
+		err := evaluateContinuousLogProbe(probe, resultDetails)
+		if err = markedVerdictInEnd(addProbePhase(err, string(chaosDetails.Phase)), resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	case "onchaos":
+		// it will check for the error, It will detect the error if any error encountered in probe during chaos
+		err := checkForErrorInContinuousProbe(resultDetails, probe.Name)
+		// failing the probe, if the matching log lines doesn't follow the comparator criteria
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onChaosLogProbe trigger the log probe for DuringChaos phase
+func onChaosLogProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) {
+
+	switch strings.ToLower(probe.Mode) {
+	case "onchaos":
+
+		//DISPLAY THE LOG PROBE INFO
+		log.InfoWithValues("[Probe]: The log probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "DuringChaos",
+		})
+		scheduleStream(chaosDetails, func(ctx context.Context) {
+			triggerOnChaosLogProbe(ctx, probe, clients, resultDetails, chaosDetails)
+		})
+	}
+}
+
+// triggerLogProbe streams the logs for the given window and evaluates the count of matching log lines
+func triggerLogProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, clients clients.ClientSets, resultDetails *types.ResultDetails, window time.Duration) error {
+	inputs, re, err := getLogProbeInputs(probe)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+
+	counter := &logCounter{}
+	if err := streamLogs(ctx, probe.Name, inputs, re, clients, counter); err != nil {
+		return err
+	}
+	return evaluateLogProbe(probe, inputs, counter, resultDetails)
+}
+
+// triggerOnChaosLogProbe streams the logs for the entire chaos duration and evaluates the count of matching log lines
+// the streaming is stopped early, if the probes are stopped by the scheduler
+func triggerOnChaosLogProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
+	duration := chaosDetails.ChaosDuration
+	// waiting for initial delay
+	if probe.RunProperties.InitialDelaySeconds != 0 {
+		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+		if !sleepWithContext(ctx, time.Duration(probe.RunProperties.InitialDelaySeconds)*time.Second) {
+			return
+		}
+		duration = math.Maximum(0, duration-probe.RunProperties.InitialDelaySeconds)
+	}
+
+	if err := triggerLogProbe(ctx, probe, clients, chaosresult, time.Duration(duration)*time.Second); err != nil {
+		recordProbeError(probe, chaosresult, chaosDetails, err)
+		// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
+		if probe.RunProperties.StopOnFailure {
+			if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
+				log.Errorf("unable to patch chaosengine to stop, err: %v", err)
+			}
+		}
+	}
+}
+
+// triggerContinuousLogProbe streams the logs till the probes are stopped by the scheduler, at the end of chaos
+// the count of matching log lines is evaluated in the postchaos phase
+func triggerContinuousLogProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, clients clients.ClientSets) {
+	// waiting for initial delay
+	if probe.RunProperties.InitialDelaySeconds != 0 {
+		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+		if !sleepWithContext(ctx, time.Duration(probe.RunProperties.InitialDelaySeconds)*time.Second) {
+			return
+		}
+	}
+
+	counter := &logCounter{}
+	continuousLogCounters.Lock()
+	continuousLogCounters.counters[probe.Name] = counter
+	continuousLogCounters.Unlock()
+
+	inputs, re, err := getLogProbeInputs(probe)
+	if err == nil {
+		err = streamLogs(ctx, probe.Name, inputs, re, clients, counter)
+	}
+	if err != nil {
+		log.Errorf("The %v log probe has been Failed, err: %v", probe.Name, err)
+		continuousLogCounters.Lock()
+		continuousLogCounters.errors[probe.Name] = err
+		continuousLogCounters.Unlock()
+	}
+}
+
+// evaluateContinuousLogProbe evaluates the log lines matched by the continuous log probe
+func evaluateContinuousLogProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) error {
+	continuousLogCounters.Lock()
+	counter, err := continuousLogCounters.counters[probe.Name], continuousLogCounters.errors[probe.Name]
+	continuousLogCounters.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if counter == nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: "log stream is not started for the probe"}
+	}
+	inputs, _, err := getLogProbeInputs(probe)
+	if err != nil {
+		return err
+	}
+	return evaluateLogProbe(probe, inputs, counter, resultDetails)
+}
+
+// evaluateLogProbe compares the count of matching log lines with the expected criteria
+// it also registers the count inside the probe artifacts
+func evaluateLogProbe(probe v1alpha1.ProbeAttributes, inputs logProbeInputs, counter *logCounter, resultDetails *types.ResultDetails) error {
+	counter.mu.Lock()
+	count, lastMatch := counter.count, counter.lastMatch
+	counter.mu.Unlock()
+
+	probes := types.ProbeArtifact{}
+	probes.ProbeArtifacts.Register = strconv.Itoa(count)
+	ResultLock.Lock()
+	resultDetails.ProbeArtifacts[probe.Name] = probes
+	ResultLock.Unlock()
+
+	rc := getAndIncrementRunCount(resultDetails, probe.Name)
+	if _, err := validateResult(inputs.Comparator, probe.Name, defaultBaselineKey, strconv.Itoa(count), rc, resultDetails, cerrors.ErrorTypeLogProbe); err != nil {
+		if lastMatch != "" {
+			return cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("%s, last matched line: '%s'", getDescription(err), lastMatch)}
+		}
+		return err
+	}
+	setProbeDescription(resultDetails, probe, fmt.Sprintf("Found %v log lines matching '%s' pattern, which follows the '%s %s' criteria", count, inputs.Pattern, inputs.Comparator.Criteria, inputs.Comparator.Value))
+	return nil
+}
+
+// getLogProbeInputs parse the log probe inputs from the data field of the probe
+func getLogProbeInputs(probe v1alpha1.ProbeAttributes) (logProbeInputs, *regexp.Regexp, error) {
+	inputs := logProbeInputs{}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &inputs); err != nil {
+		return inputs, nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to parse the log probe inputs from data, err: %v", err)}
+	}
+	if inputs.Namespace == "" || inputs.LabelSelector == "" {
+		return inputs, nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: "namespace and labelSelector are required for the log probe"}
+	}
+	re, err := regexp.Compile(inputs.Pattern)
+	if err != nil {
+		return inputs, nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("the log pattern '%s' is not a valid expression", inputs.Pattern)}
+	}
+
+	// the onchaos log probe counts over the chaos duration, which can't be compared with a pre-chaos baseline window
+	if strings.ToLower(probe.Mode) == "onchaos" && cmp.IsBaselineCriteria(inputs.Comparator.Criteria) {
+		return inputs, nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("criteria '%s' is not supported in the onchaos mode of the log probe", inputs.Comparator.Criteria)}
+	}
+
+	// setting the defaults for comparator
+	if inputs.Comparator.Type == "" {
+		inputs.Comparator.Type = "int"
+	}
+	if inputs.Comparator.Criteria == "" {
+		inputs.Comparator.Criteria = ">="
+		inputs.Comparator.Value = "1"
+	}
+	return inputs, re, nil
+}
+
+// streamLogs streams the logs of all the target pods till the context is done
+// the pods are re-listed periodically, so that the pods created in between (e.g. replacements of the killed pods) are streamed as well
+// it counts the log lines matching the given pattern
+func streamLogs(ctx context.Context, probeName string, inputs logProbeInputs, re *regexp.Regexp, clients clients.ClientSets, counter *logCounter) error {
+	start := time.Now()
+	podList, err := clients.KubeClient.CoreV1().Pods(inputs.Namespace).List(ctx, v1.ListOptions{LabelSelector: inputs.LabelSelector})
+	if err != nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("unable to list the pods with matching labels, err: %v", err)}
+	} else if len(podList.Items) == 0 {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeLogProbe, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("no pod found with {labelSelector: %s, namespace: %s}", inputs.LabelSelector, inputs.Namespace)}
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// streamed contains the uids of the pods, whose logs are already being streamed
+	streamed := map[clientTypes.UID]bool{}
+	streamPods := func(pods []apiv1.Pod) {
+		for _, pod := range pods {
+			if streamed[pod.UID] {
+				continue
+			}
+			streamed[pod.UID] = true
+			container := inputs.Container
+			if container == "" {
+				container = pod.Spec.Containers[0].Name
+			}
+			wg.Add(1)
+			go func(podName, container string) {
+				defer wg.Done()
+				streamPodLogs(ctx, inputs.Namespace, podName, container, start, re, clients, counter)
+			}(pod.Name, container)
+		}
+	}
+	streamPods(podList.Items)
+
+	ticker := time.NewTicker(logResyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			podList, err := clients.KubeClient.CoreV1().Pods(inputs.Namespace).List(ctx, v1.ListOptions{LabelSelector: inputs.LabelSelector})
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Warnf("unable to list the pods with {labelSelector: %s, namespace: %s}, err: %v", inputs.LabelSelector, inputs.Namespace, err)
+				}
+				continue
+			}
+			streamPods(podList.Items)
+		}
+	}
+}
+
+// streamPodLogs follows the logs of the given container, written after the start time, till the context is done
+// it reconnects the stream if the container restarts in between
+// the sinceTime of the reconnected stream has second granularity, so the lines are de-duplicated using their timestamps
+func streamPodLogs(ctx context.Context, namespace, podName, container string, start time.Time, re *regexp.Regexp, clients clients.ClientSets, counter *logCounter) {
+	cursor := newLogCursor(start)
+	for ctx.Err() == nil {
+		since := v1.NewTime(cursor.last)
+		stream, err := clients.KubeClient.CoreV1().Pods(namespace).GetLogs(podName, &apiv1.PodLogOptions{
+			Container:  container,
+			Follow:     true,
+			SinceTime:  &since,
+			Timestamps: true,
+		}).Stream(ctx)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				log.Warnf("%s pod not found, stopping the log stream", podName)
+				return
+			}
+			log.Warnf("unable to stream the logs of %s pod, err: %v", podName, err)
+		} else {
+			// reading the whole line, as the scanner stops at the lines longer than its buffer
+			reader := bufio.NewReader(stream)
+			for {
+				text, err := reader.ReadString('\n')
+				// the partial line of a broken stream is read again after reconnect
+				if err != nil && err != io.EOF {
+					break
+				}
+				if text = strings.TrimRight(text, "\r\n"); text != "" {
+					timestamp, line, ok := splitLogTimestamp(text)
+					// skipping the lines, which are already counted or written before the start time
+					if (!ok || cursor.advance(timestamp, line)) && re.MatchString(line) {
+						counter.mu.Lock()
+						counter.count++
+						counter.lastMatch = line
+						counter.mu.Unlock()
+					}
+				}
+				if err != nil {
+					break
+				}
+			}
+			stream.Close()
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// logCursor tracks the position of the log stream, to skip the already counted lines on reconnect
+// the since time of the logs is truncated to the seconds, so the lines of the last timestamp are tracked as well
+type logCursor struct {
+	last time.Time
+	seen map[string]bool
+}
+
+func newLogCursor(start time.Time) *logCursor {
+	return &logCursor{last: start, seen: map[string]bool{}}
+}
+
+// advance moves the cursor to the given line and returns false, if the line is already counted
+// or written before the start time
+func (c *logCursor) advance(timestamp time.Time, line string) bool {
+	switch {
+	case timestamp.Before(c.last):
+		return false
+	case timestamp.After(c.last):
+		c.last = timestamp
+		c.seen = map[string]bool{}
+	case c.seen[line]:
+		return false
+	}
+	c.seen[line] = true
+	return true
+}
+
+// splitLogTimestamp splits the timestamp, added by the kubelet, from the log line
+func splitLogTimestamp(line string) (time.Time, string, bool) {
+	parts := strings.SplitN(line, " ", 2)
+	timestamp, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	if len(parts) == 1 {
+		return timestamp, "", true
+	}
+	return timestamp, parts[1], true
+}
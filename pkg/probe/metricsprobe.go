@@ -0,0 +1,544 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/litmuschaos/litmus-go/pkg/utils/retry"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// metricsProbeInputs contains the inputs required for the metrics probe
+// it is provided inside the data field of the probe, in yaml/json format
+type metricsProbeInputs struct {
+	// Target of the metrics, supports: pod, node. Defaults to pod
+	Target string `json:"target,omitempty"`
+	// Resource of the metrics, supports: cpu, memory
+	Resource string `json:"resource,omitempty"`
+	// Namespace of the target pods, defaults to the application namespace
+	Namespace string `json:"namespace,omitempty"`
+	// LabelSelector and Names select the target pods or nodes
+	// the pods are derived from the application details, if both are not provided.
+	// the nodes are derived from the nodes of the target pods, if both are not provided
+	LabelSelector string   `json:"labelSelector,omitempty"`
+	Names         []string `json:"names,omitempty"`
+	// Container name of the target pods, the usage of all the containers is summed up, if it is not provided
+	Container string `json:"container,omitempty"`
+	// Unit of the usage, supports: percentage, absolute. Defaults to percentage
+	// the absolute cpu usage is in millicores and memory usage is in MiB
+	Unit string `json:"unit,omitempty"`
+	// RelativeTo is the base of the percentage usage of the pods, supports: limit, request. Defaults to limit
+	// the percentage usage of the nodes is always relative to the allocatable resources
+	RelativeTo string `json:"relativeTo,omitempty"`
+	// Aggregation of the usage across the targets, supports: max, avg, sum. Defaults to max
+	Aggregation string `json:"aggregation,omitempty"`
+	// Comparator check for the aggregated usage, the type defaults to float
+	Comparator v1alpha1.ComparatorInfo `json:"comparator,omitempty"`
+}
+
+var (
+	podMetricsGVR  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	nodeMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+)
+
+// prepareMetricsProbe contains the steps to prepare the metrics probe
+// metrics probe reads the cpu/memory usage of the target pods or nodes from the metrics-server
+// and compares the aggregated usage with the expected criteria
+func prepareMetricsProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, phase string) error {
+
+	switch strings.ToLower(phase) {
+	case "prechaos":
+		if err := preChaosMetricsProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "postchaos":
+		if err := postChaosMetricsProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "duringchaos":
+		onChaosMetricsProbe(probe, resultDetails, clients, chaosDetails)
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeMetricsProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("phase '%s' not supported in the metrics probe", phase)}
+	}
+	return nil
+}
+
+// preChaosMetricsProbe trigger the metrics probe for prechaos phase
+func preChaosMetricsProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "sot", "edge":
+
+		//DISPLAY THE METRICS PROBE INFO
+		log.InfoWithValues("[Probe]: The metrics probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// triggering the metrics probe
+		err := triggerMetricsProbe(context.Background(), probe, clients, resultDetails, chaosDetails)
+
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PreChaos"); err != nil {
+			return err
+		}
+	case "continuous":
+
+		//DISPLAY THE METRICS PROBE INFO
+		log.InfoWithValues("[Probe]: The metrics probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, -1, func(ctx context.Context) error {
+			return triggerMetricsProbe(ctx, probe, clients, resultDetails, chaosDetails)
+		})
+	}
+	return nil
+}
+
+// postChaosMetricsProbe trigger the metrics probe for postchaos phase
+func postChaosMetricsProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "eot", "edge":
+
+		//DISPLAY THE METRICS PROBE INFO
+		log.InfoWithValues("[Probe]: The metrics probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PostChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// triggering the metrics probe
+		err := triggerMetricsProbe(context.Background(), probe, clients, resultDetails, chaosDetails)
+
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	case "continuous", "onchaos":
+		// it will check for the error, It will detect the error if any error encountered in probe during chaos
+		err := checkForErrorInContinuousProbe(resultDetails, probe.Name)
+		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onChaosMetricsProbe trigger the metrics probe for DuringChaos phase
+func onChaosMetricsProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) {
+
+	switch strings.ToLower(probe.Mode) {
+	case "onchaos":
+
+		//DISPLAY THE METRICS PROBE INFO
+		log.InfoWithValues("[Probe]: The metrics probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "DuringChaos",
+		})
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration, func(ctx context.Context) error {
+			return triggerMetricsProbe(ctx, probe, clients, resultDetails, chaosDetails)
+		})
+	}
+}
+
+// triggerMetricsProbe reads the usage of the targets and compares the aggregated usage with the expected criteria
+func triggerMetricsProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, clients clients.ClientSets, resultDetails *types.ResultDetails, chaosDetails *types.ChaosDetails) error {
+	inputs, err := getMetricsProbeInputs(probe, chaosDetails)
+	if err != nil {
+		return err
+	}
+
+	var description string
+	// it will retry for some retry count, in each iteration of try it contains following things
+	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
+	// for a timeout, it will read the metrics, if it fails wait for the interval and again read the metrics until timeout expires
+	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
+		Timeout(int64(probe.RunProperties.ProbeTimeout)).
+		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
+		TryWithTimeout(func(attempt uint) error {
+			var usages []float64
+			var err error
+			switch inputs.Target {
+			case "node":
+				usages, err = getNodeUsages(inputs, clients, chaosDetails)
+			default:
+				usages, err = getPodUsages(inputs, clients, chaosDetails)
+			}
+			if err != nil {
+				return cerrors.Error{ErrorCode: cerrors.ErrorTypeMetricsProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
+			}
+			value := strconv.FormatFloat(aggregateUsages(usages, inputs.Aggregation), 'f', 2, 64)
+
+			rc := getAndIncrementRunCount(resultDetails, probe.Name)
+			if description, err = validateResult(inputs.Comparator, probe.Name, defaultBaselineKey, value, rc, resultDetails, cerrors.ErrorTypeMetricsProbe); err != nil {
+				log.Errorf("The %v metrics probe has been Failed, err: %v", probe.Name, err)
+				return err
+			}
+			// storing the aggregated usage and the named outputs inside the probe artifacts
+			return registerProbeArtifacts(probe, resultDetails, value, cerrors.ErrorTypeMetricsProbe)
+		}); err != nil {
+		return err
+	}
+	setProbeDescription(resultDetails, probe, description)
+	return nil
+}
+
+// getPodUsages returns the usage of each target pod
+// the usage of a pod is the sum of the usage of its containers, or the usage of the given container
+// in percentage unit, the containers without the limit or request are left out of both the usage and the base
+func getPodUsages(inputs metricsProbeInputs, clients clients.ClientSets, chaosDetails *types.ChaosDetails) ([]float64, error) {
+	pods, err := getMetricsTargetPods(inputs, clients, chaosDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []float64
+	for _, pod := range pods {
+		metrics, err := clients.DynamicClient.Resource(podMetricsGVR).Namespace(pod.Namespace).Get(context.Background(), pod.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the metrics of %v pod in %v namespace, err: %v", pod.Name, pod.Namespace, err)
+		}
+		containers, _, err := unstructured.NestedSlice(metrics.Object, "containers")
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the metrics of %v pod, err: %v", pod.Name, err)
+		}
+
+		var (
+			base    resource.Quantity
+			limited map[string]bool
+		)
+		if inputs.Unit != "absolute" {
+			if base, limited, err = getPodResourceBase(pod, inputs); err != nil {
+				return nil, err
+			}
+		}
+
+		var usage resource.Quantity
+		found := false
+		for _, container := range containers {
+			c, ok := container.(map[string]interface{})
+			if !ok || (inputs.Container != "" && c["name"] != inputs.Container) {
+				continue
+			}
+			if name, _ := c["name"].(string); limited != nil && !limited[name] {
+				continue
+			}
+			quantity, err := getUsageQuantity(c, inputs.Resource)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse the metrics of %v pod, err: %v", pod.Name, err)
+			}
+			usage.Add(quantity)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("metrics of %v pod are not available", pod.Name)
+		}
+
+		if inputs.Unit == "absolute" {
+			usages = append(usages, getAbsoluteUsage(usage, inputs.Resource))
+			continue
+		}
+		usages = append(usages, getPercentageUsage(usage, base))
+	}
+	return usages, nil
+}
+
+// getNodeUsages returns the usage of each target node
+// the percentage usage is relative to the allocatable resources of the node
+func getNodeUsages(inputs metricsProbeInputs, clients clients.ClientSets, chaosDetails *types.ChaosDetails) ([]float64, error) {
+	nodes, err := getMetricsTargetNodes(inputs, clients, chaosDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []float64
+	for _, node := range nodes {
+		metrics, err := clients.DynamicClient.Resource(nodeMetricsGVR).Get(context.Background(), node.Name, v1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the metrics of %v node, err: %v", node.Name, err)
+		}
+		usage, err := getUsageQuantity(metrics.Object, inputs.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the metrics of %v node, err: %v", node.Name, err)
+		}
+
+		if inputs.Unit == "absolute" {
+			usages = append(usages, getAbsoluteUsage(usage, inputs.Resource))
+			continue
+		}
+		base, ok := node.Status.Allocatable[corev1.ResourceName(inputs.Resource)]
+		if !ok || base.IsZero() {
+			return nil, fmt.Errorf("allocatable %v is not available for %v node", inputs.Resource, node.Name)
+		}
+		usages = append(usages, getPercentageUsage(usage, base))
+	}
+	return usages, nil
+}
+
+// getMetricsTargetPods returns the running target pods, selected by the names or label selector
+// the application details are used, if both are not provided
+func getMetricsTargetPods(inputs metricsProbeInputs, clients clients.ClientSets, chaosDetails *types.ChaosDetails) ([]corev1.Pod, error) {
+	type podSelector struct {
+		namespace, label string
+		names            []string
+	}
+
+	var selectors []podSelector
+	switch {
+	case len(inputs.Names) != 0 || inputs.LabelSelector != "":
+		selectors = append(selectors, podSelector{namespace: inputs.Namespace, label: inputs.LabelSelector, names: inputs.Names})
+	default:
+		for _, app := range chaosDetails.AppDetail {
+			switch {
+			case app.Kind == "pod":
+				selectors = append(selectors, podSelector{namespace: app.Namespace, names: app.Names})
+			default:
+				for _, label := range app.Labels {
+					if label == "" {
+						continue
+					}
+					selectors = append(selectors, podSelector{namespace: app.Namespace, label: label})
+				}
+			}
+		}
+	}
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("either names or labelSelector is required to select the target pods")
+	}
+
+	var pods []corev1.Pod
+	for _, selector := range selectors {
+		if len(selector.names) != 0 {
+			for _, name := range selector.names {
+				pod, err := clients.KubeClient.CoreV1().Pods(selector.namespace).Get(context.Background(), name, v1.GetOptions{})
+				if err != nil {
+					return nil, fmt.Errorf("unable to get the %v pod in %v namespace, err: %v", name, selector.namespace, err)
+				}
+				pods = append(pods, *pod)
+			}
+			continue
+		}
+		podList, err := clients.KubeClient.CoreV1().Pods(selector.namespace).List(context.Background(), v1.ListOptions{LabelSelector: selector.label})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the pods with %v labels in %v namespace, err: %v", selector.label, selector.namespace, err)
+		}
+		pods = append(pods, podList.Items...)
+	}
+
+	var runningPods []corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning {
+			runningPods = append(runningPods, pod)
+		}
+	}
+	if len(runningPods) == 0 {
+		return nil, fmt.Errorf("no running target pod found")
+	}
+	return runningPods, nil
+}
+
+// getMetricsTargetNodes returns the target nodes, selected by the names or label selector
+// the nodes of the target pods are used, if both are not provided
+func getMetricsTargetNodes(inputs metricsProbeInputs, clients clients.ClientSets, chaosDetails *types.ChaosDetails) ([]corev1.Node, error) {
+	names := inputs.Names
+	if len(names) == 0 && inputs.LabelSelector == "" {
+		pods, err := getMetricsTargetPods(metricsProbeInputs{Namespace: inputs.Namespace}, clients, chaosDetails)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			if !containsString(names, pod.Spec.NodeName) {
+				names = append(names, pod.Spec.NodeName)
+			}
+		}
+	}
+
+	var nodes []corev1.Node
+	if len(names) == 0 {
+		nodeList, err := clients.KubeClient.CoreV1().Nodes().List(context.Background(), v1.ListOptions{LabelSelector: inputs.LabelSelector})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the nodes with %v labels, err: %v", inputs.LabelSelector, err)
+		}
+		nodes = nodeList.Items
+	}
+	for _, name := range names {
+		node, err := clients.KubeClient.CoreV1().Nodes().Get(context.Background(), name, v1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the %v node, err: %v", name, err)
+		}
+		nodes = append(nodes, *node)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no target node found")
+	}
+	return nodes, nil
+}
+
+// getPodResourceBase returns the sum of the limits or requests of the target containers of the pod, along with the accounted containers
+// without the container filter, the containers which don't define the limit or request are skipped
+func getPodResourceBase(pod corev1.Pod, inputs metricsProbeInputs) (resource.Quantity, map[string]bool, error) {
+	var base resource.Quantity
+	containers := map[string]bool{}
+	for _, container := range pod.Spec.Containers {
+		if inputs.Container != "" && container.Name != inputs.Container {
+			continue
+		}
+		resources := container.Resources.Limits
+		if inputs.RelativeTo == "request" {
+			resources = container.Resources.Requests
+		}
+		quantity, ok := resources[corev1.ResourceName(inputs.Resource)]
+		if !ok || quantity.IsZero() {
+			if inputs.Container == "" {
+				continue
+			}
+			return base, nil, fmt.Errorf("%v %v is not defined for %v container of %v pod", inputs.Resource, inputs.RelativeTo, container.Name, pod.Name)
+		}
+		base.Add(quantity)
+		containers[container.Name] = true
+	}
+	if base.IsZero() {
+		if inputs.Container == "" {
+			return base, nil, fmt.Errorf("%v %v is not defined for any container of %v pod", inputs.Resource, inputs.RelativeTo, pod.Name)
+		}
+		return base, nil, fmt.Errorf("%v container is not found in %v pod", inputs.Container, pod.Name)
+	}
+	return base, containers, nil
+}
+
+// getUsageQuantity parse the usage of the given resource from the metrics
+func getUsageQuantity(metrics map[string]interface{}, resourceName string) (resource.Quantity, error) {
+	value, found, err := unstructured.NestedString(metrics, "usage", resourceName)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	if !found {
+		return resource.Quantity{}, fmt.Errorf("%v usage is not available", resourceName)
+	}
+	return resource.ParseQuantity(value)
+}
+
+// getAbsoluteUsage returns the cpu usage in millicores and memory usage in MiB
+func getAbsoluteUsage(usage resource.Quantity, resourceName string) float64 {
+	if resourceName == "cpu" {
+		return float64(usage.MilliValue())
+	}
+	return float64(usage.Value()) / (1024 * 1024)
+}
+
+// getPercentageUsage returns the usage in percentage of the base
+func getPercentageUsage(usage, base resource.Quantity) float64 {
+	return float64(usage.MilliValue()) * 100 / float64(base.MilliValue())
+}
+
+// aggregateUsages aggregates the usages of the targets by max, avg or sum
+func aggregateUsages(usages []float64, aggregation string) float64 {
+	var result float64
+	for index, usage := range usages {
+		switch aggregation {
+		case "max":
+			if index == 0 || usage > result {
+				result = usage
+			}
+		default:
+			result += usage
+		}
+	}
+	if aggregation == "avg" && len(usages) != 0 {
+		result /= float64(len(usages))
+	}
+	return result
+}
+
+// getMetricsProbeInputs parse the metrics probe inputs from the data field of the probe
+func getMetricsProbeInputs(probe v1alpha1.ProbeAttributes, chaosDetails *types.ChaosDetails) (metricsProbeInputs, error) {
+	inputs := metricsProbeInputs{}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &inputs); err != nil {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeMetricsProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to parse the metrics probe inputs from data, err: %v", err)}
+	}
+
+	// setting the defaults for the optional inputs
+	inputs.Target = strings.ToLower(inputs.Target)
+	if inputs.Target == "" {
+		inputs.Target = "pod"
+	}
+	inputs.Resource = strings.ToLower(inputs.Resource)
+	inputs.Unit = strings.ToLower(inputs.Unit)
+	if inputs.Unit == "" {
+		inputs.Unit = "percentage"
+	}
+	inputs.RelativeTo = strings.ToLower(inputs.RelativeTo)
+	if inputs.RelativeTo == "" {
+		inputs.RelativeTo = "limit"
+	}
+	inputs.Aggregation = strings.ToLower(inputs.Aggregation)
+	if inputs.Aggregation == "" {
+		inputs.Aggregation = "max"
+	}
+	if inputs.Namespace == "" {
+		inputs.Namespace = chaosDetails.ChaosNamespace
+		if len(chaosDetails.AppDetail) != 0 {
+			inputs.Namespace = chaosDetails.AppDetail[0].Namespace
+		}
+	}
+	if inputs.Comparator.Type == "" {
+		inputs.Comparator.Type = "float"
+	}
+
+	var reason string
+	switch {
+	case inputs.Target != "pod" && inputs.Target != "node":
+		reason = fmt.Sprintf("target '%s' not supported in the metrics probe", inputs.Target)
+	case inputs.Resource != "cpu" && inputs.Resource != "memory":
+		reason = fmt.Sprintf("resource '%s' not supported in the metrics probe", inputs.Resource)
+	case inputs.Unit != "percentage" && inputs.Unit != "absolute":
+		reason = fmt.Sprintf("unit '%s' not supported in the metrics probe", inputs.Unit)
+	case inputs.RelativeTo != "limit" && inputs.RelativeTo != "request":
+		reason = fmt.Sprintf("relativeTo '%s' not supported in the metrics probe", inputs.RelativeTo)
+	case inputs.Aggregation != "max" && inputs.Aggregation != "avg" && inputs.Aggregation != "sum":
+		reason = fmt.Sprintf("aggregation '%s' not supported in the metrics probe", inputs.Aggregation)
+	case inputs.Comparator.Criteria == "":
+		reason = "comparator criteria is required for the metrics probe"
+	}
+	if reason != "" {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeMetricsProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: reason}
+	}
+	return inputs, nil
+}
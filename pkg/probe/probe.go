@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"html/template"
 	"strings"
+	"text/template"
 
 	"github.com/kyokomi/emoji"
 	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
@@ -18,10 +18,8 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var err error
-
 // RunProbes contains the steps to trigger the probes
-// It contains steps to trigger all three probes: k8sprobe, httpprobe, cmdprobe
+// It contains steps to trigger all the probes: k8sprobe, httpprobe, cmdprobe, promprobe, watchprobe, logprobe, dbprobe, compositeprobe
 func RunProbes(chaosDetails *types.ChaosDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, phase string, eventsDetails *types.EventDetails) error {
 
 	// get the probes details from the chaosengine
@@ -29,6 +27,11 @@ func RunProbes(chaosDetails *types.ChaosDetails, clients clients.ClientSets, res
 	if err != nil {
 		return err
 	}
+	// composite probes are evaluated after the probes referenced by them
+	probes, err = prepareCompositeProbes(probes, resultDetails)
+	if err != nil {
+		return err
+	}
 
 	switch strings.ToLower(phase) {
 	//execute probes for the prechaos phase
@@ -39,6 +42,9 @@ func RunProbes(chaosDetails *types.ChaosDetails, clients clients.ClientSets, res
 				if err := execute(probe, chaosDetails, clients, resultDetails, phase); err != nil {
 					return err
 				}
+			case "eot", "onchaos":
+				// record the pre-chaos baseline value, if the probe compares the value relative to the baseline
+				captureBaseline(probe, chaosDetails, clients, resultDetails)
 			}
 		}
 	//execute probes for the duringchaos phase
@@ -51,14 +57,20 @@ func RunProbes(chaosDetails *types.ChaosDetails, clients clients.ClientSets, res
 			}
 		}
 	default:
+		// stopping all the continuous and onchaos probes, as the chaos is ended
+		stopProbeLoops()
 		// execute the probes for the postchaos phase
 		// it first evaluate the onchaos and continuous modes then it evaluates the other modes
 		// as onchaos and continuous probes are already completed
+		// the composite probes are evaluated in the end, after all the probes referenced by them
 		var probeError []string
 		for _, probe := range probes {
 			// evaluate continuous and onchaos probes
 			switch strings.ToLower(probe.Mode) {
 			case "onchaos", "continuous":
+				if isCompositeProbe(probe) {
+					continue
+				}
 				if err := execute(probe, chaosDetails, clients, resultDetails, phase); err != nil {
 					probeError = append(probeError, stacktrace.RootCause(err).Error())
 				}
@@ -71,6 +83,21 @@ func RunProbes(chaosDetails *types.ChaosDetails, clients clients.ClientSets, res
 		for _, probe := range probes {
 			switch strings.ToLower(probe.Mode) {
 			case "eot", "edge":
+				if isCompositeProbe(probe) {
+					continue
+				}
+				if err := execute(probe, chaosDetails, clients, resultDetails, phase); err != nil {
+					return err
+				}
+			}
+		}
+		// executes the composite probes of all the modes
+		for _, probe := range probes {
+			switch strings.ToLower(probe.Mode) {
+			case "onchaos", "continuous", "eot", "edge":
+				if !isCompositeProbe(probe) {
+					continue
+				}
 				if err := execute(probe, chaosDetails, clients, resultDetails, phase); err != nil {
 					return err
 				}
@@ -103,6 +130,8 @@ func setProbeVerdict(resultDetails *types.ResultDetails, probe v1alpha1.ProbeAtt
 
 // setProbeDescription sets the description to probe
 func setProbeDescription(resultDetails *types.ResultDetails, probe v1alpha1.ProbeAttributes, description string) {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
 	for index, probes := range resultDetails.ProbeDetails {
 		if probes.Name == probe.Name && probes.Type == probe.Type {
 			resultDetails.ProbeDetails[index].Status.Description = description
@@ -121,14 +150,24 @@ func SetProbeVerdictAfterFailure(result *v1alpha1.ChaosResult) {
 	}
 }
 
+// getProbesFromChaosEngine returns the probes of the experiment
+// the probes resolved during the initialization are reused, so that the library probes are not fetched in every phase
 func getProbesFromChaosEngine(chaosDetails *types.ChaosDetails, clients clients.ClientSets) ([]v1alpha1.ProbeAttributes, error) {
+	if chaosDetails.Probes != nil {
+		return chaosDetails.Probes, nil
+	}
 	engine, err := types.GetChaosEngine(chaosDetails, clients)
 	if err != nil {
 		return nil, err
 	}
 	for _, exp := range engine.Spec.Experiments {
 		if exp.Name == chaosDetails.ExperimentName {
-			return exp.Spec.Probe, nil
+			probes, err := types.ResolveProbes(chaosDetails, clients, exp.Spec.Probe)
+			if err != nil {
+				return nil, err
+			}
+			chaosDetails.Probes = append([]v1alpha1.ProbeAttributes{}, probes...)
+			return chaosDetails.Probes, nil
 		}
 	}
 	return nil, nil
@@ -136,6 +175,8 @@ func getProbesFromChaosEngine(chaosDetails *types.ChaosDetails, clients clients.
 
 // getAndIncrementRunCount return the run count for the specified probe
 func getAndIncrementRunCount(resultDetails *types.ResultDetails, probeName string) int {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
 	for index, probe := range resultDetails.ProbeDetails {
 		if probeName == probe.Name {
 			resultDetails.ProbeDetails[index].RunCount++
@@ -149,6 +190,8 @@ func getAndIncrementRunCount(resultDetails *types.ResultDetails, probeName strin
 // which will used in the continuous cmd probe, run_id is used as suffix in the external pod name
 func getRunIDFromProbe(resultDetails *types.ResultDetails, probeName, probeType string) string {
 
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
 	for _, probe := range resultDetails.ProbeDetails {
 		if probe.Name == probeName && probe.Type == probeType {
 			return probe.RunID
@@ -160,6 +203,8 @@ func getRunIDFromProbe(resultDetails *types.ResultDetails, probeName, probeType
 // setRunIDForProbe set the run_id for the dedicated probe.
 // which will used in the continuous cmd probe, run_id is used as suffix in the external pod name
 func setRunIDForProbe(resultDetails *types.ResultDetails, probeName, probeType, runid string) {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
 	for index, probe := range resultDetails.ProbeDetails {
 		if probe.Name == probeName && probe.Type == probeType {
 			resultDetails.ProbeDetails[index].RunID = runid
@@ -170,6 +215,9 @@ func setRunIDForProbe(resultDetails *types.ResultDetails, probeName, probeType,
 
 // markedVerdictInEnd add the probe status in the chaosresult
 func markedVerdictInEnd(err error, resultDetails *types.ResultDetails, probe v1alpha1.ProbeAttributes, phase string) error {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+
 	probeVerdict := v1alpha1.ProbeVerdictPassed
 	var description string
 	if err != nil {
@@ -206,6 +254,12 @@ func markedVerdictInEnd(err error, resultDetails *types.ResultDetails, probe v1a
 
 	setProbeVerdict(resultDetails, probe, probeVerdict, description, phase)
 
+	// recording the outcome of the latest evaluation, which is used by the composite probes
+	if probeDetails := getProbeByName(probe.Name, resultDetails.ProbeDetails); probeDetails != nil {
+		probeDetails.LastVerdict = probeVerdict
+		probeDetails.LastError = err
+	}
+
 	if err != nil {
 		switch probe.RunProperties.StopOnFailure {
 		case true:
@@ -244,9 +298,15 @@ func getDescription(err error) string {
 
 // CheckForErrorInContinuousProbe check for the error in the continuous probes
 func checkForErrorInContinuousProbe(resultDetails *types.ResultDetails, probeName string) error {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
 
 	for index, probe := range resultDetails.ProbeDetails {
 		if probe.Name == probeName {
+			// evaluating the success rate of the iterations, if the evaluation policy is provided
+			if probe.IsProbeFailedWithError == nil && probe.Evaluation != nil {
+				return evaluateSuccessRate(resultDetails.ProbeDetails[index])
+			}
 			return resultDetails.ProbeDetails[index].IsProbeFailedWithError
 		}
 	}
@@ -257,13 +317,16 @@ func checkForErrorInContinuousProbe(resultDetails *types.ResultDetails, probeNam
 // if command doesn't have template, it will return the same command
 func parseCommand(templatedCommand string, resultDetails *types.ResultDetails) (string, error) {
 
-	register := resultDetails.ProbeArtifacts
-
-	t := template.Must(template.New("t1").Parse(templatedCommand))
+	t, err := template.New("t1").Funcs(templateFuncs()).Parse(templatedCommand)
+	if err != nil {
+		return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("failed to parse the templated command, %s", err.Error())}
+	}
 
 	// store the parsed output in the buffer
 	var out bytes.Buffer
-	if err := t.Execute(&out, register); err != nil {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+	if err := t.Execute(&out, resultDetails.ProbeArtifacts); err != nil {
 		return "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("failed to parse the templated command, %s", err.Error())}
 	}
 
@@ -273,7 +336,7 @@ func parseCommand(templatedCommand string, resultDetails *types.ResultDetails) (
 // stopChaosEngine update the probe status and patch the chaosengine to stop state
 func stopChaosEngine(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) error {
 	// it will check for the error, It will detect the error if any error encountered in probe during chaos
-	err = checkForErrorInContinuousProbe(chaosresult, probe.Name)
+	err := checkForErrorInContinuousProbe(chaosresult, probe.Name)
 	// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 	markedVerdictInEnd(err, chaosresult, probe, "PostChaos")
 	//patch chaosengine's state to stop
@@ -292,6 +355,7 @@ func stopChaosEngine(probe v1alpha1.ProbeAttributes, clients clients.ClientSets,
 
 // execute contains steps to execute & evaluate probes in different modes at different phases
 func execute(probe v1alpha1.ProbeAttributes, chaosDetails *types.ChaosDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, phase string) error {
+	var err error
 	switch strings.ToLower(probe.Type) {
 	case "k8sprobe":
 		// it contains steps to prepare the k8s probe
@@ -313,6 +377,36 @@ func execute(probe v1alpha1.ProbeAttributes, chaosDetails *types.ChaosDetails, c
 		if err = preparePromProbe(probe, clients, chaosDetails, resultDetails, phase); err != nil {
 			return stacktrace.Propagate(err, "probes failed")
 		}
+	case "watchprobe":
+		// it contains steps to prepare watch probe
+		if err = prepareWatchProbe(probe, clients, chaosDetails, resultDetails, phase); err != nil {
+			return stacktrace.Propagate(err, "probes failed")
+		}
+	case "logprobe":
+		// it contains steps to prepare log probe
+		if err = prepareLogProbe(probe, clients, chaosDetails, resultDetails, phase); err != nil {
+			return stacktrace.Propagate(err, "probes failed")
+		}
+	case "dbprobe":
+		// it contains steps to prepare db probe
+		if err = prepareDBProbe(probe, clients, chaosDetails, resultDetails, phase); err != nil {
+			return stacktrace.Propagate(err, "probes failed")
+		}
+	case "metricsprobe":
+		// it contains steps to prepare metrics probe
+		if err = prepareMetricsProbe(probe, clients, chaosDetails, resultDetails, phase); err != nil {
+			return stacktrace.Propagate(err, "probes failed")
+		}
+	case "kafkaprobe":
+		// it contains steps to prepare kafka probe
+		if err = prepareKafkaProbe(probe, clients, chaosDetails, resultDetails, phase); err != nil {
+			return stacktrace.Propagate(err, "probes failed")
+		}
+	case "compositeprobe":
+		// it contains steps to prepare composite probe
+		if err = prepareCompositeProbe(probe, chaosDetails, resultDetails, phase); err != nil {
+			return stacktrace.Propagate(err, "probes failed")
+		}
 	default:
 		return stacktrace.Propagate(err, "%v probe type not supported", probe.Type)
 	}
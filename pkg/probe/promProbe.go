@@ -2,6 +2,7 @@ package probe
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -11,7 +12,6 @@ import (
 	"github.com/litmuschaos/litmus-go/pkg/cerrors"
 	"github.com/litmuschaos/litmus-go/pkg/clients"
 	"github.com/litmuschaos/litmus-go/pkg/log"
-	"github.com/litmuschaos/litmus-go/pkg/math"
 	cmp "github.com/litmuschaos/litmus-go/pkg/probe/comparator"
 	"github.com/litmuschaos/litmus-go/pkg/types"
 	"github.com/litmuschaos/litmus-go/pkg/utils/retry"
@@ -44,6 +44,7 @@ func preparePromProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets
 // preChaosPromProbe trigger the prometheus probe for prechaos phase
 func preChaosPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
 
+	var err error
 	switch strings.ToLower(probe.Mode) {
 	case "sot", "edge":
 
@@ -65,7 +66,7 @@ func preChaosPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 		}
 
 		// triggering the prom probe and storing the output into the out buffer
-		err = triggerPromProbe(probe, resultDetails)
+		err = triggerPromProbe(context.Background(), probe, resultDetails)
 
 		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 		// it will update the status of all the unrun probes as well
@@ -87,7 +88,9 @@ func preChaosPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 		})
 
 		// trigger the continuous cmd probe
-		go triggerContinuousPromProbe(probe, clients, resultDetails, chaosDetails)
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, -1, func(ctx context.Context) error {
+			return triggerPromProbe(ctx, probe, resultDetails)
+		})
 	}
 
 	return nil
@@ -96,6 +99,7 @@ func preChaosPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resu
 // postChaosPromProbe trigger the prometheus probe for postchaos phase
 func postChaosPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
 
+	var err error
 	switch strings.ToLower(probe.Mode) {
 	case "eot", "edge":
 
@@ -117,7 +121,7 @@ func postChaosPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Res
 		}
 
 		// triggering the prom probe and storing the output into the out buffer
-		err = triggerPromProbe(probe, resultDetails)
+		err = triggerPromProbe(context.Background(), probe, resultDetails)
 
 		// failing the probe, if the success condition doesn't met after the retry & timeout combinations
 		// it will update the status of all the unrun probes as well
@@ -157,19 +161,31 @@ func onChaosPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 		})
 
 		// trigger the continuous prom probe
-		go triggerOnChaosPromProbe(probe, clients, resultDetails, chaosDetails)
+		scheduleProbe(probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration, func(ctx context.Context) error {
+			return triggerPromProbe(ctx, probe, resultDetails)
+		})
 	}
 	return nil
 }
 
 // triggerPromProbe trigger the prometheus probe inside the external pod
-func triggerPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) error {
+func triggerPromProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails) error {
+	var err error
 	var description string
+
+	// It parses the templated query and return normal string
+	// if query doesn't have template, it will return the same query
+	probe.PromProbeInputs.Query, err = parseCommand(probe.PromProbeInputs.Query, resultDetails)
+	if err != nil {
+		return err
+	}
+
 	// running the prom probe command and matching the output
 	// it will retry for some retry count, in each iteration of try it contains following things
 	// it contains a timeout per iteration of retry. if the timeout expires without success then it will go to next try
 	// for a timeout, it will run the command, if it fails wait for the interval and again execute the command until timeout expires
 	if err := retry.Times(uint(getAttempts(probe.RunProperties.Attempt, probe.RunProperties.Retry))).
+		Context(ctx).
 		Timeout(int64(probe.RunProperties.ProbeTimeout)).
 		Wait(time.Duration(probe.RunProperties.Interval) * time.Millisecond).
 		TryWithTimeout(func(attempt uint) error {
@@ -186,7 +202,7 @@ func triggerPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 
 			var out, errOut bytes.Buffer
 			// run the inline command probe
-			cmd := exec.Command("/bin/sh", "-c", command)
+			cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
 			cmd.Stdout = &out
 			cmd.Stderr = &errOut
 			if err := cmd.Run(); err != nil {
@@ -200,6 +216,21 @@ func triggerPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 			}
 
 			rc := getAndIncrementRunCount(resultDetails, probe.Name)
+			// comparing the metrics output relative to the baseline value, recorded in the first evaluation of the probe
+			if cmp.IsBaselineCriteria(probe.PromProbeInputs.Comparator.Criteria) {
+				compare := cmp.RunCount(rc).
+					FirstValue(value).
+					SecondValue(probe.PromProbeInputs.Comparator.Value).
+					Criteria(probe.PromProbeInputs.Comparator.Criteria).
+					ProbeName(probe.Name)
+				if description, err = compareWithBaseline(compare, probe.PromProbeInputs.Comparator, probe.Name, defaultBaselineKey, value, resultDetails, cerrors.ErrorTypePromProbe); err != nil {
+					log.Errorf("The %v prom probe has been Failed, err: %v", probe.Name, err)
+					return err
+				}
+				// storing the metrics value and the named outputs inside the probe artifacts
+				return registerProbeArtifacts(probe, resultDetails, value, cerrors.ErrorTypePromProbe)
+			}
+
 			// comparing the metrics output with the expected criteria
 			if err = cmp.RunCount(rc).
 				FirstValue(value).
@@ -211,7 +242,8 @@ func triggerPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 				return err
 			}
 			description = fmt.Sprintf("Probe responded with a valid prometheus metrics value. Actual and Expected status values are %s and %s respectively", value, probe.PromProbeInputs.Comparator.Value)
-			return nil
+			// storing the metrics value and the named outputs inside the probe artifacts
+			return registerProbeArtifacts(probe, resultDetails, value, cerrors.ErrorTypePromProbe)
 		}); err != nil {
 		return err
 	}
@@ -219,98 +251,6 @@ func triggerPromProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.Resul
 	return nil
 }
 
-// triggerContinuousPromProbe trigger the continuous prometheus probe
-func triggerContinuousPromProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-
-	var isExperimentFailed bool
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-	}
-
-	// it trigger the prom probe for the entire duration of chaos and it fails, if any err encounter
-	// it marked the error for the probes, if any
-loop:
-	for {
-		err = triggerPromProbe(probe, chaosresult)
-		// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-		if err != nil {
-			err = addProbePhase(err, string(chaosDetails.Phase))
-			for index := range chaosresult.ProbeDetails {
-				if chaosresult.ProbeDetails[index].Name == probe.Name {
-					chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-					chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-					log.Errorf("The %v prom probe has been Failed, err: %v", probe.Name, err)
-					isExperimentFailed = true
-					break loop
-				}
-			}
-		}
-		// waiting for the probe polling interval
-		time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
-}
-
-// triggerOnChaosPromProbe trigger the onchaos prom probe
-func triggerOnChaosPromProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails) {
-
-	var isExperimentFailed bool
-	duration := chaosDetails.ChaosDuration
-	// waiting for initial delay
-	if probe.RunProperties.InitialDelaySeconds != 0 {
-		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
-		time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
-		duration = math.Maximum(0, duration-probe.RunProperties.InitialDelaySeconds)
-	}
-
-	endTime := time.After(time.Duration(duration) * time.Second)
-
-	// it trigger the prom probe for the entire duration of chaos and it fails, if any err encounter
-	// it marked the error for the probes, if any
-loop:
-	for {
-		select {
-		case <-endTime:
-			log.Infof("[Chaos]: Time is up for the %v probe", probe.Name)
-			endTime = nil
-			break loop
-		default:
-			// record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
-			if err = triggerPromProbe(probe, chaosresult); err != nil {
-				err = addProbePhase(err, string(chaosDetails.Phase))
-				for index := range chaosresult.ProbeDetails {
-					if chaosresult.ProbeDetails[index].Name == probe.Name {
-						chaosresult.ProbeDetails[index].IsProbeFailedWithError = err
-						chaosresult.ProbeDetails[index].Status.Description = getDescription(err)
-						log.Errorf("The %v prom probe has been Failed, err: %v", probe.Name, err)
-						isExperimentFailed = true
-						break loop
-					}
-				}
-			}
-			// waiting for the probe polling interval
-			time.Sleep(time.Duration(probe.RunProperties.ProbePollingInterval) * time.Second)
-		}
-	}
-	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
-	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
-	// and failed the experiment in the end
-	if isExperimentFailed && probe.RunProperties.StopOnFailure {
-		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
-			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
-		}
-	}
-}
-
 // extractValueFromMetrics extract the value field from the prometheus metrix
 func extractValueFromMetrics(metrics, probeName string) (string, error) {
 
@@ -0,0 +1,213 @@
+package probe
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/math"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+)
+
+// probeScheduler owns the loops of the continuous and onchaos probes
+// it limits the number of concurrent probe executions, adds jitter to the polling intervals
+// and stops all the loops through a single cancellation, once the chaos is ended or aborted
+type probeScheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// slots limits the concurrent probe executions, it is nil if there is no limit
+	slots chan struct{}
+	// jitter is the maximum deviation of the polling interval, in percentage
+	jitter int
+}
+
+var (
+	// scheduler is configured while scheduling the first probe loop
+	scheduler     = newProbeScheduler()
+	schedulerOnce sync.Once
+	// ResultLock guards the probe details and artifacts inside the chaosresult,
+	// as they are updated by the probe loops concurrently
+	ResultLock sync.Mutex
+)
+
+func init() {
+	// the probe loops are stopped by the abort watcher through the abort hook
+	types.RegisterAbortHook(abortProbeLoops)
+}
+
+// newProbeScheduler creates the probe scheduler with its cancellation context
+func newProbeScheduler() *probeScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &probeScheduler{ctx: ctx, cancel: cancel}
+}
+
+// getScheduler returns the probe scheduler, it sets the concurrency and jitter while scheduling the first probe loop
+func getScheduler(chaosDetails *types.ChaosDetails) *probeScheduler {
+	schedulerOnce.Do(func() {
+		scheduler.jitter = chaosDetails.ProbeJitter
+		if chaosDetails.ProbeConcurrency > 0 {
+			scheduler.slots = make(chan struct{}, chaosDetails.ProbeConcurrency)
+		}
+	})
+	return scheduler
+}
+
+// stopProbeLoops cancels all the probe loops and waits for the completion of the in-flight iterations
+// it is called once the chaos is ended, before evaluating the continuous and onchaos probes
+func stopProbeLoops() {
+	log.Info("[Probe]: Stopping all the continuous and onchaos probes")
+	scheduler.cancel()
+	scheduler.wg.Wait()
+}
+
+// abortProbeLoops cancels all the probe loops, without waiting for the in-flight iterations
+// it is registered as the abort hook, which is run once the abort signal is received
+func abortProbeLoops() {
+	scheduler.cancel()
+}
+
+// scheduleProbe schedules the loop of the continuous or onchaos probe, which triggers the probe at every polling interval
+// the loop runs for the given duration (in seconds), it runs till the end of chaos if the duration is negative
+// the loop is stopped on the first failed iteration, unless the evaluation policy tolerates it
+// the trigger receives the context of the scheduler, so that the in-flight iteration is stopped on cancellation
+func scheduleProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails, duration int, trigger func(ctx context.Context) error) {
+	s := getScheduler(chaosDetails)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if failed := s.run(probe, chaosresult, chaosDetails, duration, trigger); failed && probe.RunProperties.StopOnFailure {
+			// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
+			// if experiment fails but stopOnfailure is provided as false then it will continue the execution
+			// and failed the experiment in the end
+			if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
+				log.Errorf("unable to patch chaosengine to stop, err: %v", err)
+			}
+		}
+	}()
+}
+
+// scheduleStream schedules the streaming probes, which watch the target for the entire duration
+// these probes don't occupy the concurrency slots, as they run for the entire duration and are stopped via the context
+func scheduleStream(chaosDetails *types.ChaosDetails, stream func(ctx context.Context)) {
+	s := getScheduler(chaosDetails)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		stream(s.ctx)
+	}()
+}
+
+// run triggers the probe at every polling interval till the end of duration or cancellation
+// it returns true if the probe has been failed
+func (s *probeScheduler) run(probe v1alpha1.ProbeAttributes, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails, duration int, trigger func(ctx context.Context) error) bool {
+	// waiting for initial delay
+	if probe.RunProperties.InitialDelaySeconds != 0 {
+		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+		if !sleepWithContext(s.ctx, time.Duration(probe.RunProperties.InitialDelaySeconds)*time.Second) {
+			return false
+		}
+		if duration >= 0 {
+			duration = math.Maximum(0, duration-probe.RunProperties.InitialDelaySeconds)
+		}
+	}
+
+	var endTime time.Time
+	if duration >= 0 {
+		endTime = time.Now().Add(time.Duration(duration) * time.Second)
+	}
+
+	// it triggers the probe for the entire duration of chaos and it fails, if any error encounter
+	// it marked the error for the probes, if any
+	for {
+		if duration >= 0 && !time.Now().Before(endTime) {
+			log.Infof("[Chaos]: Time is up for the %v probe", probe.Name)
+			return false
+		}
+		if !s.acquire() {
+			return false
+		}
+		err := trigger(s.ctx)
+		s.release()
+
+		// the iteration interrupted by the cancellation is not recorded
+		if s.ctx.Err() != nil {
+			return false
+		}
+
+		if err = recordProbeIteration(probe, chaosresult, chaosDetails, err); err != nil {
+			recordProbeError(probe, chaosresult, chaosDetails, err)
+			return true
+		}
+
+		// waiting for the probe polling interval
+		interval := s.getInterval(probe.RunProperties.ProbePollingInterval)
+		if duration >= 0 {
+			interval = time.Duration(math.Minimum(int(interval), int(time.Until(endTime))))
+		}
+		if !sleepWithContext(s.ctx, interval) {
+			return false
+		}
+	}
+}
+
+// acquire occupies a concurrency slot, it returns false if the scheduler is cancelled
+func (s *probeScheduler) acquire() bool {
+	if s.slots == nil {
+		return s.ctx.Err() == nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// release frees the occupied concurrency slot
+func (s *probeScheduler) release() {
+	if s.slots != nil {
+		<-s.slots
+	}
+}
+
+// getInterval returns the polling interval, randomly deviated by the jitter percentage
+// so that the probes with same interval don't hit the targets at the same time
+func (s *probeScheduler) getInterval(pollingInterval int) time.Duration {
+	interval := time.Duration(pollingInterval) * time.Second
+	if s.jitter <= 0 || interval == 0 {
+		return interval
+	}
+	deviation := int64(interval) * int64(math.Minimum(s.jitter, 100)) / 100
+	return interval + time.Duration(rand.Int63n(2*deviation+1)-deviation)
+}
+
+// sleepWithContext waits for the given duration, it returns false if the context is cancelled
+func sleepWithContext(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// recordProbeError record the error inside the probeDetails, we are maintaining a dedicated variable for the err, inside probeDetails
+func recordProbeError(probe v1alpha1.ProbeAttributes, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails, err error) {
+	ResultLock.Lock()
+	defer ResultLock.Unlock()
+
+	err = addProbePhase(err, string(chaosDetails.Phase))
+	if probeDetails := getProbeByName(probe.Name, chaosresult.ProbeDetails); probeDetails != nil {
+		probeDetails.IsProbeFailedWithError = err
+		probeDetails.Status.Description = getDescription(err)
+		log.Errorf("The %v %v has been Failed, err: %v", probe.Name, strings.TrimSuffix(strings.ToLower(probe.Type), "probe")+" probe", err)
+	}
+}
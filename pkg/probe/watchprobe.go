@@ -0,0 +1,567 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	"github.com/litmuschaos/litmus-go/pkg/math"
+	"github.com/litmuschaos/litmus-go/pkg/types"
+	"github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// watchProbeInputs contains the inputs required for the watch probe
+// it is provided inside the data field of the probe, in yaml/json format
+type watchProbeInputs struct {
+	// Namespaces contains the namespaces, which need to be watched
+	Namespaces []string `json:"namespaces,omitempty"`
+	// LabelSelector filters the pods (and the events of those pods) under watch
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// FailOn contains the conditions, which fail the probe
+	FailOn watchConditions `json:"failOn,omitempty"`
+}
+
+// watchConditions contains the pod states and event reasons, which fail the watch probe
+type watchConditions struct {
+	// CrashLoopBackOff fails the probe if any container goes into CrashLoopBackOff state
+	CrashLoopBackOff bool `json:"crashLoopBackOff,omitempty"`
+	// OOMKilled fails the probe if any container gets OOMKilled
+	OOMKilled bool `json:"oomKilled,omitempty"`
+	// MaxRestarts fails the probe if any container restarts more than the given count during the probe window
+	MaxRestarts *int32 `json:"maxRestarts,omitempty"`
+	// FailedScheduling fails the probe if any pod fails to get scheduled
+	FailedScheduling bool `json:"failedScheduling,omitempty"`
+	// EventReasons fails the probe if any event with the given reasons is generated
+	EventReasons []string `json:"eventReasons,omitempty"`
+}
+
+// prepareWatchProbe contains the steps to prepare the watch probe
+// watch probe watches the pods and events for the probe window and fails on the given conditions
+func prepareWatchProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosDetails *types.ChaosDetails, resultDetails *types.ResultDetails, phase string) error {
+
+	switch strings.ToLower(phase) {
+	case "prechaos":
+		if err := preChaosWatchProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "postchaos":
+		if err := postChaosWatchProbe(probe, resultDetails, clients, chaosDetails); err != nil {
+			return err
+		}
+	case "duringchaos":
+		onChaosWatchProbe(probe, resultDetails, clients, chaosDetails)
+	default:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeWatchProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("phase '%s' not supported in the watch probe", phase)}
+	}
+	return nil
+}
+
+// preChaosWatchProbe trigger the watch probe for prechaos phase
+func preChaosWatchProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "sot", "edge":
+
+		//DISPLAY THE WATCH PROBE INFO
+		log.InfoWithValues("[Probe]: The watch probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// watching for the probe timeout window
+		err := triggerWatchProbe(probe, clients, resultDetails, time.Duration(probe.RunProperties.ProbeTimeout)*time.Millisecond)
+
+		// failing the probe, if any of the conditions met inside the probe window
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PreChaos"); err != nil {
+			return err
+		}
+	case "continuous":
+
+		//DISPLAY THE WATCH PROBE INFO
+		log.InfoWithValues("[Probe]: The watch probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PreChaos",
+		})
+		scheduleStream(chaosDetails, func(ctx context.Context) {
+			triggerContinuousWatchProbe(ctx, probe, clients, resultDetails, chaosDetails, -1)
+		})
+	}
+	return nil
+}
+
+// postChaosWatchProbe trigger the watch probe for postchaos phase
+func postChaosWatchProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) error {
+
+	switch strings.ToLower(probe.Mode) {
+	case "eot", "edge":
+
+		//DISPLAY THE WATCH PROBE INFO
+		log.InfoWithValues("[Probe]: The watch probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "PostChaos",
+		})
+		// waiting for initial delay
+		if probe.RunProperties.InitialDelaySeconds != 0 {
+			log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+			time.Sleep(time.Duration(probe.RunProperties.InitialDelaySeconds) * time.Second)
+		}
+		// watching for the probe timeout window
+		err := triggerWatchProbe(probe, clients, resultDetails, time.Duration(probe.RunProperties.ProbeTimeout)*time.Millisecond)
+
+		// failing the probe, if any of the conditions met inside the probe window
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	case "continuous", "onchaos":
+		// it will check for the error, It will detect the error if any error encountered in probe during chaos
+		err := checkForErrorInContinuousProbe(resultDetails, probe.Name)
+		// failing the probe, if any of the conditions met inside the probe window
+		if err = markedVerdictInEnd(err, resultDetails, probe, "PostChaos"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onChaosWatchProbe trigger the watch probe for DuringChaos phase
+func onChaosWatchProbe(probe v1alpha1.ProbeAttributes, resultDetails *types.ResultDetails, clients clients.ClientSets, chaosDetails *types.ChaosDetails) {
+
+	switch strings.ToLower(probe.Mode) {
+	case "onchaos":
+
+		//DISPLAY THE WATCH PROBE INFO
+		log.InfoWithValues("[Probe]: The watch probe information is as follows", logrus.Fields{
+			"Name":           probe.Name,
+			"Inputs":         probe.Data,
+			"Run Properties": probe.RunProperties,
+			"Mode":           probe.Mode,
+			"Phase":          "DuringChaos",
+		})
+		scheduleStream(chaosDetails, func(ctx context.Context) {
+			triggerContinuousWatchProbe(ctx, probe, clients, resultDetails, chaosDetails, chaosDetails.ChaosDuration)
+		})
+	}
+}
+
+// triggerWatchProbe watches the pods and events for the given window
+// it returns the error containing all the offending objects, if any
+func triggerWatchProbe(probe v1alpha1.ProbeAttributes, clients clients.ClientSets, resultDetails *types.ResultDetails, window time.Duration) error {
+	inputs, err := getWatchProbeInputs(probe)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), window)
+	defer cancel()
+
+	recorder := newWatchRecorder()
+	errCh := recorder.start(ctx, inputs, clients)
+
+	select {
+	case <-ctx.Done():
+	case err := <-errCh:
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeWatchProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()}
+	}
+
+	if violations := recorder.getViolations(); len(violations) != 0 {
+		return getWatchProbeError(probe.Name, violations)
+	}
+	setProbeDescription(resultDetails, probe, fmt.Sprintf("No offending pod states or events found inside the %v window", window))
+	return nil
+}
+
+// triggerContinuousWatchProbe watches the pods and events for the entire chaos duration
+// it runs until the probes are stopped by the scheduler, at the end of chaos, if the duration is negative
+func triggerContinuousWatchProbe(ctx context.Context, probe v1alpha1.ProbeAttributes, clients clients.ClientSets, chaosresult *types.ResultDetails, chaosDetails *types.ChaosDetails, duration int) {
+	var isExperimentFailed bool
+	// waiting for initial delay
+	if probe.RunProperties.InitialDelaySeconds != 0 {
+		log.Infof("[Wait]: Waiting for %vs before probe execution", probe.RunProperties.InitialDelaySeconds)
+		if !sleepWithContext(ctx, time.Duration(probe.RunProperties.InitialDelaySeconds)*time.Second) {
+			return
+		}
+		if duration >= 0 {
+			duration = math.Maximum(0, duration-probe.RunProperties.InitialDelaySeconds)
+		}
+	}
+
+	inputs, err := getWatchProbeInputs(probe)
+	if err != nil {
+		recordProbeError(probe, chaosresult, chaosDetails, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var endTime <-chan time.Time
+	if duration >= 0 {
+		endTime = time.After(time.Duration(duration) * time.Second)
+	}
+
+	recorder := newWatchRecorder()
+	errCh := recorder.start(ctx, inputs, clients)
+
+	// it records all the violations, encountered during the chaos
+	// the probe will be evaluated in the postchaos phase, unless stopOnFailure is enabled
+loop:
+	for {
+		select {
+		case <-endTime:
+			log.Infof("[Chaos]: Time is up for the %v probe", probe.Name)
+			break loop
+		case <-ctx.Done():
+			// the probes are stopped by the scheduler
+			break loop
+		case err := <-errCh:
+			recordProbeError(probe, chaosresult, chaosDetails, cerrors.Error{ErrorCode: cerrors.ErrorTypeWatchProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: err.Error()})
+			isExperimentFailed = true
+			break loop
+		case <-recorder.notify:
+			recordProbeError(probe, chaosresult, chaosDetails, getWatchProbeError(probe.Name, recorder.getViolations()))
+			isExperimentFailed = true
+			if probe.RunProperties.StopOnFailure {
+				break loop
+			}
+		}
+	}
+	// if experiment fails and stopOnfailure is provided as true then it will patch the chaosengine for abort
+	// if experiment fails but stopOnfailure is provided as false then it will continue the execution
+	// and failed the experiment in the end
+	if isExperimentFailed && probe.RunProperties.StopOnFailure {
+		if err := stopChaosEngine(probe, clients, chaosresult, chaosDetails); err != nil {
+			log.Errorf("unable to patch chaosengine to stop, err: %v", err)
+		}
+	}
+}
+
+// getWatchProbeInputs parse the watch probe inputs from the data field of the probe
+func getWatchProbeInputs(probe v1alpha1.ProbeAttributes) (watchProbeInputs, error) {
+	inputs := watchProbeInputs{}
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &inputs); err != nil {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeWatchProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: fmt.Sprintf("unable to parse the watch probe inputs from data, err: %v", err)}
+	}
+	if len(inputs.Namespaces) == 0 {
+		return inputs, cerrors.Error{ErrorCode: cerrors.ErrorTypeWatchProbe, Target: fmt.Sprintf("{name: %v}", probe.Name), Reason: "provide atleast one namespace to watch"}
+	}
+	if inputs.FailOn.FailedScheduling {
+		inputs.FailOn.EventReasons = append(inputs.FailOn.EventReasons, "FailedScheduling")
+	}
+	return inputs, nil
+}
+
+// getWatchProbeError returns the watch probe error containing the offending objects
+func getWatchProbeError(probeName string, violations []string) error {
+	return cerrors.Error{ErrorCode: cerrors.ErrorTypeWatchProbe, Target: fmt.Sprintf("{name: %v}", probeName), Reason: fmt.Sprintf("found offending objects: [%s]", strings.Join(violations, ","))}
+}
+
+// watchRecorder records the violations, observed by the pod and event watchers
+// the states observed before the probe window are recorded as the baseline and are not considered as violations
+type watchRecorder struct {
+	mu         sync.Mutex
+	violations []string
+	seen       map[string]bool
+	restarts   map[string]int32
+	crashLoop  map[string]bool
+	oomKilled  map[string]v1.Time
+	startTime  time.Time
+	notify     chan struct{}
+}
+
+// newWatchRecorder creates an instance of the watch recorder
+func newWatchRecorder() *watchRecorder {
+	return &watchRecorder{
+		seen:      map[string]bool{},
+		restarts:  map[string]int32{},
+		crashLoop: map[string]bool{},
+		oomKilled: map[string]v1.Time{},
+		startTime: time.Now(),
+		notify:    make(chan struct{}, 1),
+	}
+}
+
+// start starts the pod and event watchers for all the namespaces
+// it returns a channel, which receives the watcher errors
+func (w *watchRecorder) start(ctx context.Context, inputs watchProbeInputs, clients clients.ClientSets) <-chan error {
+	errCh := make(chan error, 2*len(inputs.Namespaces))
+	for _, ns := range inputs.Namespaces {
+		ns := strings.TrimSpace(ns)
+		go func() {
+			if err := w.watchPods(ctx, ns, inputs, clients); err != nil {
+				errCh <- err
+			}
+		}()
+		if len(inputs.FailOn.EventReasons) != 0 {
+			go func() {
+				if err := w.watchEvents(ctx, ns, inputs, clients); err != nil {
+					errCh <- err
+				}
+			}()
+		}
+	}
+	return errCh
+}
+
+// record records the violation, if not already recorded
+func (w *watchRecorder) record(key, violation string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.seen[key] {
+		return
+	}
+	w.seen[key] = true
+	w.violations = append(w.violations, violation)
+	log.Errorf("[Probe]: Found offending object: %v", violation)
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// getViolations returns all the recorded violations
+func (w *watchRecorder) getViolations() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string{}, w.violations...)
+}
+
+// watchPods watches the pods with matching labels and evaluates their container states
+func (w *watchRecorder) watchPods(ctx context.Context, namespace string, inputs watchProbeInputs, clients clients.ClientSets) error {
+	// the states of the initial listing are recorded as the baseline
+	resourceVersion, err := w.listPods(ctx, namespace, inputs, clients, true)
+	if err != nil || resourceVersion == "" {
+		return err
+	}
+
+	for {
+		watcher, err := clients.KubeClient.CoreV1().Pods(namespace).Watch(ctx, v1.ListOptions{LabelSelector: inputs.LabelSelector, ResourceVersion: resourceVersion})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("unable to watch the pods in %s namespace, err: %v", namespace, err)
+		}
+		expired := false
+		for event := range watcher.ResultChan() {
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if pod, ok := event.Object.(*apiv1.Pod); ok {
+					resourceVersion = pod.ResourceVersion
+					w.evaluatePod(pod, inputs.FailOn, false)
+				}
+			case watch.Error:
+				expired = true
+			}
+		}
+		watcher.Stop()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if expired {
+			// resource version is too old, re-listing the pods to catch up the missed updates
+			// the baselines are retained, so the states observed before the probe window are not reported
+			if resourceVersion, err = w.listPods(ctx, namespace, inputs, clients, false); err != nil || resourceVersion == "" {
+				return err
+			}
+		}
+	}
+}
+
+// listPods lists the pods with matching labels, evaluates them and returns the resource version of the list
+// it returns empty resource version, if the context is already cancelled
+func (w *watchRecorder) listPods(ctx context.Context, namespace string, inputs watchProbeInputs, clients clients.ClientSets, initial bool) (string, error) {
+	podList, err := clients.KubeClient.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: inputs.LabelSelector})
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to list the pods in %s namespace, err: %v", namespace, err)
+	}
+	for index := range podList.Items {
+		w.evaluatePod(&podList.Items[index], inputs.FailOn, initial)
+	}
+	return podList.ResourceVersion, nil
+}
+
+// evaluatePod evaluates the container states of the pod against the given conditions
+// on the initial evaluation, it only records the baseline of the restarts, crashloop and oomkilled states
+func (w *watchRecorder) evaluatePod(pod *apiv1.Pod, failOn watchConditions, initial bool) {
+	var containers []apiv1.ContainerStatus
+	containers = append(containers, pod.Status.InitContainerStatuses...)
+	containers = append(containers, pod.Status.ContainerStatuses...)
+
+	for _, container := range containers {
+		key := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, container.Name)
+		crashLoop := container.State.Waiting != nil && container.State.Waiting.Reason == "CrashLoopBackOff"
+		oomKilledAt, oomKilled := getOOMKilledTime(container)
+
+		w.mu.Lock()
+		if initial {
+			w.restarts[key] = container.RestartCount
+			w.crashLoop[key] = crashLoop
+			if oomKilled {
+				w.oomKilled[key] = oomKilledAt
+			}
+			w.mu.Unlock()
+			continue
+		}
+		// containers created inside the probe window starts with zero restarts
+		baseline := w.restarts[key]
+		crashLoopBaseline := w.crashLoop[key]
+		oomKilledBaseline, oomKilledBefore := w.oomKilled[key]
+		w.mu.Unlock()
+
+		// the containers, which are already in crashloop before the probe window, are reported only via restarts
+		if failOn.CrashLoopBackOff && crashLoop && !crashLoopBaseline {
+			w.record(key+"/CrashLoopBackOff", fmt.Sprintf("{pod: %s, namespace: %s, container: %s, reason: CrashLoopBackOff}", pod.Name, pod.Namespace, container.Name))
+		}
+		if failOn.OOMKilled && oomKilled && (!oomKilledBefore || !oomKilledAt.Equal(&oomKilledBaseline)) {
+			w.record(key+"/OOMKilled", fmt.Sprintf("{pod: %s, namespace: %s, container: %s, reason: OOMKilled}", pod.Name, pod.Namespace, container.Name))
+		}
+		if failOn.MaxRestarts != nil && container.RestartCount-baseline > *failOn.MaxRestarts {
+			w.record(key+"/Restarts", fmt.Sprintf("{pod: %s, namespace: %s, container: %s, reason: restarted %v times, which is more than %v}", pod.Name, pod.Namespace, container.Name, container.RestartCount-baseline, *failOn.MaxRestarts))
+		}
+	}
+}
+
+// getOOMKilledTime returns the finish time of the latest OOMKilled termination from the current or last state of the container
+func getOOMKilledTime(container apiv1.ContainerStatus) (v1.Time, bool) {
+	if container.State.Terminated != nil && container.State.Terminated.Reason == "OOMKilled" {
+		return container.State.Terminated.FinishedAt, true
+	}
+	if container.LastTerminationState.Terminated != nil && container.LastTerminationState.Terminated.Reason == "OOMKilled" {
+		return container.LastTerminationState.Terminated.FinishedAt, true
+	}
+	return v1.Time{}, false
+}
+
+// watchEvents watches the events generated inside the probe window, having the given reasons
+// if label selector is provided, it only considers the events of the matching pods
+func (w *watchRecorder) watchEvents(ctx context.Context, namespace string, inputs watchProbeInputs, clients clients.ClientSets) error {
+	selector, err := labels.Parse(inputs.LabelSelector)
+	if err != nil {
+		return fmt.Errorf("invalid label selector '%s', err: %v", inputs.LabelSelector, err)
+	}
+
+	// listing the events to start the watch from the current resource version, ignoring the older events
+	eventList, err := clients.KubeClient.CoreV1().Events(namespace).List(ctx, v1.ListOptions{Limit: 1})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("unable to list the events in %s namespace, err: %v", namespace, err)
+	}
+
+	resourceVersion := eventList.ResourceVersion
+	for {
+		watcher, err := clients.KubeClient.CoreV1().Events(namespace).Watch(ctx, v1.ListOptions{ResourceVersion: resourceVersion})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("unable to watch the events in %s namespace, err: %v", namespace, err)
+		}
+		expired := false
+		for event := range watcher.ResultChan() {
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if ev, ok := event.Object.(*apiv1.Event); ok {
+					resourceVersion = ev.ResourceVersion
+					w.evaluateEvent(ctx, ev, selector, inputs, clients)
+				}
+			case watch.Error:
+				expired = true
+			}
+		}
+		watcher.Stop()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if expired {
+			// resource version is too old, re-listing the events to catch up the missed events
+			// the events, which are older than the probe window, are filtered by their timestamps
+			eventList, err := clients.KubeClient.CoreV1().Events(namespace).List(ctx, v1.ListOptions{})
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("unable to list the events in %s namespace, err: %v", namespace, err)
+			}
+			for index := range eventList.Items {
+				w.evaluateEvent(ctx, &eventList.Items[index], selector, inputs, clients)
+			}
+			resourceVersion = eventList.ResourceVersion
+		}
+	}
+}
+
+// evaluateEvent records the event, if it is generated inside the probe window and has one of the given reasons
+func (w *watchRecorder) evaluateEvent(ctx context.Context, ev *apiv1.Event, selector labels.Selector, inputs watchProbeInputs, clients clients.ClientSets) {
+	if !containsString(inputs.FailOn.EventReasons, ev.Reason) || getEventTime(ev).Before(w.startTime) {
+		return
+	}
+	if inputs.LabelSelector != "" && !isEventOfMatchingPod(ctx, ev, selector, clients) {
+		return
+	}
+	w.record(string(ev.UID), fmt.Sprintf("{event: %s, kind: %s, name: %s, namespace: %s, reason: %s, message: %s}", ev.Name, ev.InvolvedObject.Kind, ev.InvolvedObject.Name, ev.Namespace, ev.Reason, strings.TrimSpace(ev.Message)))
+}
+
+// getEventTime returns the time of the latest occurrence of the event
+func getEventTime(ev *apiv1.Event) time.Time {
+	switch {
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	default:
+		return ev.FirstTimestamp.Time
+	}
+}
+
+// isEventOfMatchingPod checks whether the event belongs to a pod with matching labels
+func isEventOfMatchingPod(ctx context.Context, event *apiv1.Event, selector labels.Selector, clients clients.ClientSets) bool {
+	if event.InvolvedObject.Kind != "Pod" {
+		return false
+	}
+	pod, err := clients.KubeClient.CoreV1().Pods(event.InvolvedObject.Namespace).Get(ctx, event.InvolvedObject.Name, v1.GetOptions{})
+	if err != nil {
+		log.Warnf("unable to get the %s pod of %s event, err: %v", event.InvolvedObject.Name, event.Name, err)
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+// containsString checks whether the value is present inside the list, ignoring case
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package result
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -24,6 +25,14 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// probeBaselinesAnnotation is the annotation of the chaosresult, which contains the baseline values of the probes
+	// keyed by the probe name and the compared field or resource
+	probeBaselinesAnnotation = "litmuschaos.io/probe-baselines"
+	// probeEvaluationsAnnotation is the annotation of the chaosresult, which contains the evaluation outcome of the probes
+	probeEvaluationsAnnotation = "litmuschaos.io/probe-evaluations"
+)
+
 // ChaosResult Create and Update the chaos result
 func ChaosResult(chaosDetails *types.ChaosDetails, clients clients.ClientSets, resultDetails *types.ResultDetails, state string) error {
 	experimentLabel := map[string]string{}
@@ -132,6 +141,10 @@ func GetProbeStatus(resultDetails *types.ResultDetails) (bool, bool, []v1alpha1.
 	isAllProbePassed := true
 	experimentStopped := false
 
+	// the probe details are updated by the continuous and onchaos probes concurrently
+	probe.ResultLock.Lock()
+	defer probe.ResultLock.Unlock()
+
 	probeStatus := []v1alpha1.ProbeStatuses{}
 	for _, probe := range resultDetails.ProbeDetails {
 		probes := v1alpha1.ProbeStatuses{}
@@ -140,7 +153,8 @@ func GetProbeStatus(resultDetails *types.ResultDetails) (bool, bool, []v1alpha1.
 		probes.Mode = probe.Mode
 		probes.Status = probe.Status
 		probeStatus = append(probeStatus, probes)
-		if probe.Status.Verdict == v1alpha1.ProbeVerdictFailed {
+		// verdict of the probes referenced by a composite probe is accounted by the composite probe
+		if probe.Status.Verdict == v1alpha1.ProbeVerdictFailed && probe.Composite == "" {
 			isAllProbePassed = false
 			if probe.Stopped {
 				experimentStopped = probe.Stopped
@@ -157,7 +171,7 @@ func getFailStep(probeDetails []*types.ProbeDetails, phase string) (string, stri
 		rootCause string
 	)
 	for _, probe := range probeDetails {
-		if probe.IsProbeFailedWithError != nil {
+		if probe.IsProbeFailedWithError != nil && probe.Composite == "" {
 			rootCause, errCode = cerrors.GetRootCauseAndErrorCode(probe.IsProbeFailedWithError, phase)
 			errList = append(errList, rootCause)
 		}
@@ -191,6 +205,9 @@ func updateResultAttributes(clients clients.ClientSets, chaosDetails *types.Chao
 	result.Status.History.Targets = chaosDetails.Targets
 	isAllProbePassed, experimentStopped, result.Status.ProbeStatuses = GetProbeStatus(resultDetails)
 	result.Status.ExperimentStatus.Verdict = resultDetails.Verdict
+	if err := setProbeAnnotations(result, resultDetails); err != nil {
+		return nil, stacktrace.Propagate(err, "could not set the probe annotations")
+	}
 
 	switch strings.ToLower(string(resultDetails.Phase)) {
 	case "completed", "error", "stopped":
@@ -418,3 +435,32 @@ func getExperimentPhaseFromResultPhase(phase v1alpha1.ResultPhase) string {
 	}
 	return ""
 }
+
+// setProbeAnnotations persists the baseline values and the evaluation outcome of the probes inside the chaosresult annotations
+// so that these are available in the structured form after the experiment
+func setProbeAnnotations(result *v1alpha1.ChaosResult, resultDetails *types.ResultDetails) error {
+	if baselines := probe.GetProbeBaselines(resultDetails); len(baselines) != 0 {
+		if err := setJSONAnnotation(result, probeBaselinesAnnotation, baselines); err != nil {
+			return err
+		}
+	}
+	if evaluations := probe.GetProbeEvaluations(resultDetails); len(evaluations) != 0 {
+		if err := setJSONAnnotation(result, probeEvaluationsAnnotation, evaluations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setJSONAnnotation sets the json encoded value as the annotation of the chaosresult
+func setJSONAnnotation(result *v1alpha1.ChaosResult, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeChaosResultCRUD, Target: fmt.Sprintf("{name: %s, namespace: %s}", result.Name, result.Namespace), Reason: fmt.Sprintf("unable to marshal the %s annotation: %s", key, err.Error())}
+	}
+	if result.Annotations == nil {
+		result.Annotations = map[string]string{}
+	}
+	result.Annotations[key] = string(data)
+	return nil
+}
@@ -0,0 +1,273 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
+	"github.com/litmuschaos/litmus-go/pkg/cerrors"
+	"github.com/litmuschaos/litmus-go/pkg/clients"
+	"github.com/litmuschaos/litmus-go/pkg/log"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// LibraryProbeType is the type of the probe, which refers to a probe definition stored inside the probe library
+const LibraryProbeType = "libraryProbe"
+
+// parameterRegex matches the $(params.<name>) placeholders inside the probe definition
+var parameterRegex = regexp.MustCompile(`\$\(params\.([A-Za-z0-9_-]+)\)`)
+
+// libraryProbeRef contains the reference of the library probe, provided inside the data field of the probe
+type libraryProbeRef struct {
+	Source libraryProbeSource `json:"source"`
+	// Parameters contains the values of the placeholders, it takes precedence over the default values
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Overrides contains the fields of the probe definition, which needs to be overridden
+	Overrides map[string]interface{} `json:"overrides,omitempty"`
+}
+
+// libraryProbeSource contains the location of the probe definition
+// the definition is read either from a key of the configmap or from the spec of the probe custom resource
+type libraryProbeSource struct {
+	ConfigMap string `json:"configMap,omitempty"`
+	// Key is the key of the configmap, it defaults to the name of the probe
+	Key   string               `json:"key,omitempty"`
+	Probe *probeResourceSource `json:"probe,omitempty"`
+	// Namespace defaults to the chaos namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// probeResourceSource contains the details of the probe custom resource
+type probeResourceSource struct {
+	Group    string `json:"group,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	Name     string `json:"name"`
+}
+
+// libraryProbeDefinition is the probe definition stored inside the probe library
+type libraryProbeDefinition struct {
+	// Parameters contains the default values of the placeholders used inside the probe
+	Parameters map[string]string      `json:"parameters,omitempty"`
+	Probe      map[string]interface{} `json:"probe"`
+}
+
+// ResolveProbes replaces the library probes with the probe definitions referred by them
+// the rest of the probes are returned as it is
+func ResolveProbes(chaosDetails *ChaosDetails, clients clients.ClientSets, probes []v1alpha1.ProbeAttributes) ([]v1alpha1.ProbeAttributes, error) {
+	var resolvedProbes []v1alpha1.ProbeAttributes
+	for _, probe := range probes {
+		if !strings.EqualFold(probe.Type, LibraryProbeType) {
+			resolvedProbes = append(resolvedProbes, probe)
+			continue
+		}
+		resolvedProbe, err := resolveLibraryProbe(probe, chaosDetails, clients)
+		if err != nil {
+			return nil, err
+		}
+		resolvedProbes = append(resolvedProbes, resolvedProbe)
+	}
+	return resolvedProbes, nil
+}
+
+// resolveLibraryProbe derives the probe from the library definition
+// the placeholders are replaced with the parameters, the name, mode and runProperties provided
+// inside the chaosengine take precedence over the definition and the overrides are applied in the end
+func resolveLibraryProbe(probe v1alpha1.ProbeAttributes, chaosDetails *ChaosDetails, clients clients.ClientSets) (v1alpha1.ProbeAttributes, error) {
+	var ref libraryProbeRef
+	if err := sigsyaml.Unmarshal([]byte(probe.Data), &ref); err != nil {
+		return v1alpha1.ProbeAttributes{}, libraryProbeError(probe.Name, fmt.Sprintf("unable to parse the probe reference, err: %v", err))
+	}
+
+	definition, source, err := getLibraryProbeDefinition(probe.Name, ref.Source, chaosDetails, clients)
+	if err != nil {
+		return v1alpha1.ProbeAttributes{}, err
+	}
+
+	parameters := map[string]string{}
+	for name, value := range definition.Parameters {
+		parameters[name] = value
+	}
+	for name, value := range ref.Parameters {
+		parameters[name] = value
+	}
+	missing := map[string]bool{}
+	spec := substituteParameters(definition.Probe, parameters, missing).(map[string]interface{})
+	if len(missing) != 0 {
+		var names []string
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return v1alpha1.ProbeAttributes{}, libraryProbeError(probe.Name, fmt.Sprintf("values of the parameters %v are not provided", names))
+	}
+
+	spec["name"] = probe.Name
+	if probe.Mode != "" {
+		spec["mode"] = probe.Mode
+	}
+	runProperties, err := toMap(probe.RunProperties)
+	if err != nil {
+		return v1alpha1.ProbeAttributes{}, libraryProbeError(probe.Name, err.Error())
+	}
+	mergeValues(spec, map[string]interface{}{"runProperties": runProperties})
+	mergeValues(spec, ref.Overrides)
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return v1alpha1.ProbeAttributes{}, libraryProbeError(probe.Name, err.Error())
+	}
+	var resolvedProbe v1alpha1.ProbeAttributes
+	if err := json.Unmarshal(data, &resolvedProbe); err != nil {
+		return v1alpha1.ProbeAttributes{}, libraryProbeError(probe.Name, fmt.Sprintf("invalid probe definition in %v, err: %v", source, err))
+	}
+
+	switch {
+	case resolvedProbe.Type == "":
+		return v1alpha1.ProbeAttributes{}, libraryProbeError(probe.Name, fmt.Sprintf("probe type is not defined in %v", source))
+	case strings.EqualFold(resolvedProbe.Type, LibraryProbeType):
+		return v1alpha1.ProbeAttributes{}, libraryProbeError(probe.Name, fmt.Sprintf("nested library probe is not supported, found in %v", source))
+	}
+
+	log.Infof("[Probe]: The %v probe is resolved from %v", probe.Name, source)
+	return resolvedProbe, nil
+}
+
+// getLibraryProbeDefinition reads the probe definition from the configmap or the probe custom resource
+// it returns the definition along with the description of the source
+func getLibraryProbeDefinition(probeName string, source libraryProbeSource, chaosDetails *ChaosDetails, clients clients.ClientSets) (libraryProbeDefinition, string, error) {
+	var definition libraryProbeDefinition
+
+	namespace := source.Namespace
+	if namespace == "" {
+		namespace = chaosDetails.ChaosNamespace
+	}
+
+	switch {
+	case source.ConfigMap != "" && source.Probe != nil:
+		return definition, "", libraryProbeError(probeName, "only one of the configMap or probe source should be provided")
+	case source.ConfigMap != "":
+		key := source.Key
+		if key == "" {
+			key = probeName
+		}
+		description := fmt.Sprintf("{configMap: %v, namespace: %v, key: %v}", source.ConfigMap, namespace, key)
+
+		configMap, err := clients.KubeClient.CoreV1().ConfigMaps(namespace).Get(context.Background(), source.ConfigMap, v1.GetOptions{})
+		if err != nil {
+			return definition, "", libraryProbeError(probeName, fmt.Sprintf("unable to get the probe library %v, err: %v", description, err))
+		}
+		value, ok := configMap.Data[key]
+		if !ok {
+			return definition, "", libraryProbeError(probeName, fmt.Sprintf("probe definition is not found in %v", description))
+		}
+		if err := sigsyaml.Unmarshal([]byte(value), &definition); err != nil {
+			return definition, "", libraryProbeError(probeName, fmt.Sprintf("unable to parse the probe definition in %v, err: %v", description, err))
+		}
+		return definition, description, validateDefinition(probeName, definition, description)
+	case source.Probe != nil:
+		gvr := schema.GroupVersionResource{
+			Group:    source.Probe.Group,
+			Version:  source.Probe.Version,
+			Resource: source.Probe.Resource,
+		}
+		if gvr.Group == "" {
+			gvr.Group = "litmuschaos.io"
+		}
+		if gvr.Version == "" {
+			gvr.Version = "v1alpha1"
+		}
+		if gvr.Resource == "" {
+			gvr.Resource = "chaosprobes"
+		}
+		description := fmt.Sprintf("{resource: %v, name: %v, namespace: %v}", gvr.String(), source.Probe.Name, namespace)
+
+		resource, err := clients.DynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), source.Probe.Name, v1.GetOptions{})
+		if err != nil {
+			return definition, "", libraryProbeError(probeName, fmt.Sprintf("unable to get the probe library %v, err: %v", description, err))
+		}
+		spec, found, err := unstructured.NestedMap(resource.Object, "spec")
+		if err != nil || !found {
+			return definition, "", libraryProbeError(probeName, fmt.Sprintf("spec is not found in %v", description))
+		}
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return definition, "", libraryProbeError(probeName, err.Error())
+		}
+		if err := json.Unmarshal(data, &definition); err != nil {
+			return definition, "", libraryProbeError(probeName, fmt.Sprintf("unable to parse the probe definition in %v, err: %v", description, err))
+		}
+		return definition, description, validateDefinition(probeName, definition, description)
+	default:
+		return definition, "", libraryProbeError(probeName, "either configMap or probe source should be provided")
+	}
+}
+
+// validateDefinition checks whether the probe is defined inside the library definition
+func validateDefinition(probeName string, definition libraryProbeDefinition, description string) error {
+	if len(definition.Probe) == 0 {
+		return libraryProbeError(probeName, fmt.Sprintf("probe is not defined in %v", description))
+	}
+	return nil
+}
+
+// substituteParameters replaces the placeholders inside all the string values of the probe definition
+// the names of the parameters without any value are added to the missing parameters
+func substituteParameters(value interface{}, parameters map[string]string, missing map[string]bool) interface{} {
+	switch v := value.(type) {
+	case string:
+		return parameterRegex.ReplaceAllStringFunc(v, func(placeholder string) string {
+			name := parameterRegex.FindStringSubmatch(placeholder)[1]
+			if parameter, ok := parameters[name]; ok {
+				return parameter
+			}
+			missing[name] = true
+			return placeholder
+		})
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = substituteParameters(val, parameters, missing)
+		}
+	case []interface{}:
+		for index, val := range v {
+			v[index] = substituteParameters(val, parameters, missing)
+		}
+	}
+	return value
+}
+
+// mergeValues merges the overrides into the values recursively
+// the nested maps are merged and rest of the values are replaced
+func mergeValues(values, overrides map[string]interface{}) {
+	for key, override := range overrides {
+		overrideMap, isMap := override.(map[string]interface{})
+		valueMap, ok := values[key].(map[string]interface{})
+		if isMap && ok {
+			mergeValues(valueMap, overrideMap)
+			continue
+		}
+		values[key] = override
+	}
+}
+
+// toMap converts the given value to the generic map
+func toMap(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	return result, json.Unmarshal(data, &result)
+}
+
+// libraryProbeError returns the library probe error for the given probe
+func libraryProbeError(probeName, reason string) error {
+	return cerrors.Error{ErrorCode: cerrors.ErrorTypeLibraryProbe, Target: fmt.Sprintf("{name: %v}", probeName), Reason: reason}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/litmuschaos/litmus-go/pkg/cerrors"
@@ -18,10 +19,17 @@ import (
 	"github.com/litmuschaos/chaos-operator/api/litmuschaos/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	clientTypes "k8s.io/apimachinery/pkg/types"
+	sigsyaml "sigs.k8s.io/yaml"
 )
 
 var err error
 
+var (
+	// abortHooks are run by the abort watcher of the experiment, once the abort signal is received
+	abortHooks     []func()
+	abortHooksLock sync.Mutex
+)
+
 const (
 	SideCarEnabled = "sidecar/enabled"
 	SideCarPrefix  = "SIDECAR"
@@ -76,6 +84,7 @@ type ProbeArtifact struct {
 // RegisterDetails contains the output of the corresponding probe
 type RegisterDetails struct {
 	Register string
+	Outputs  map[string]string
 }
 
 // ProbeDetails is for collecting all the probe details
@@ -89,6 +98,32 @@ type ProbeDetails struct {
 	RunID                  string
 	RunCount               int
 	Stopped                bool
+	Composite              string
+	LastVerdict            v1alpha1.ProbeVerdict
+	LastError              error
+	Evaluation             *EvaluationDetails
+	Baselines              map[string]string
+	RecordBaseline         bool
+}
+
+// EvaluationPolicy contains the policy to evaluate the continuous and onchaos probes
+// if it is not provided, the probe fails on the first failed iteration
+type EvaluationPolicy struct {
+	// SuccessRate is the minimum percentage of the passed iterations
+	SuccessRate float64 `json:"successRate,omitempty"`
+	// MaxConsecutiveFailures is the maximum number of consecutive failed iterations, it is ignored if not provided
+	MaxConsecutiveFailures int `json:"maxConsecutiveFailures,omitempty"`
+}
+
+// EvaluationDetails is for collecting the evaluation policy and the outcome of the iterations
+// of the continuous and onchaos probes
+type EvaluationDetails struct {
+	SuccessRate            float64
+	MaxConsecutiveFailures int
+	Iterations             int
+	PassedIterations       int
+	ConsecutiveFailures    int
+	LastError              error
 }
 
 // EventDetails is for collecting all the events-related details
@@ -114,6 +149,8 @@ type ChaosDetails struct {
 	ChaosDuration        int
 	JobCleanupPolicy     string
 	ProbeImagePullPolicy string
+	ProbeConcurrency     int
+	ProbeJitter          int
 	Randomness           bool
 	Targets              []v1alpha1.TargetDetails
 	ParentsResources     []ParentResource
@@ -124,6 +161,10 @@ type ChaosDetails struct {
 	Labels               map[string]string
 	Phase                ExperimentPhase
 	SideCar              []SideCar
+	// Probes contains the probes of the experiment, the library probes are resolved once and reused in all the phases
+	Probes []v1alpha1.ProbeAttributes
+	// ProbeEvaluationPolicies contains the evaluation policies of the probes, keyed by the probe name
+	ProbeEvaluationPolicies map[string]EvaluationPolicy
 }
 
 type SideCar struct {
@@ -199,11 +240,47 @@ func InitialiseChaosVariables(chaosDetails *ChaosDetails) {
 	chaosDetails.DefaultHealthCheck, _ = strconv.ParseBool(Getenv("DEFAULT_HEALTH_CHECK", "true"))
 	chaosDetails.JobCleanupPolicy = Getenv("JOB_CLEANUP_POLICY", "retain")
 	chaosDetails.ProbeImagePullPolicy = Getenv("LIB_IMAGE_PULL_POLICY", "Always")
+	chaosDetails.ProbeConcurrency, _ = strconv.Atoi(Getenv("PROBE_CONCURRENCY", "0"))
+	chaosDetails.ProbeJitter, _ = strconv.Atoi(Getenv("PROBE_JITTER_PERCENTAGE", "0"))
 	chaosDetails.ParentsResources = []ParentResource{}
 	chaosDetails.Targets = []v1alpha1.TargetDetails{}
 	chaosDetails.Phase = PreChaosPhase
 }
 
+// getProbeEvaluationPolicies parses the evaluation policies of the probes, provided in the yaml/json map format
+// the policies are applicable only for the continuous and onchaos probes, which are evaluated in every iteration
+// the streaming probes (watch & log) are evaluated once at the end, so the policies are not supported for them
+func getProbeEvaluationPolicies(value string, probes []v1alpha1.ProbeAttributes) (map[string]EvaluationPolicy, error) {
+	policies := map[string]EvaluationPolicy{}
+	if strings.TrimSpace(value) == "" {
+		return policies, nil
+	}
+	if err := sigsyaml.Unmarshal([]byte(value), &policies); err != nil {
+		return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("unable to parse the probe evaluation policies, err: %v", err)}
+	}
+
+	for name, policy := range policies {
+		var probe *v1alpha1.ProbeAttributes
+		for i := range probes {
+			if probes[i].Name == name {
+				probe = &probes[i]
+				break
+			}
+		}
+		switch {
+		case probe == nil:
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{name: %v}", name), Reason: "evaluation policy is provided for a probe, which is not defined in the experiment"}
+		case strings.ToLower(probe.Mode) != "continuous" && strings.ToLower(probe.Mode) != "onchaos":
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{name: %v}", name), Reason: fmt.Sprintf("evaluation policy is not supported in the '%v' mode, it is supported only for the continuous and onchaos probes", probe.Mode)}
+		case strings.ToLower(probe.Type) == "watchprobe" || strings.ToLower(probe.Type) == "logprobe":
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{name: %v}", name), Reason: fmt.Sprintf("evaluation policy is not supported for the %v, it is evaluated once at the end of the chaos", probe.Type)}
+		case policy.SuccessRate < 0 || policy.SuccessRate > 100 || policy.MaxConsecutiveFailures < 0:
+			return nil, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Target: fmt.Sprintf("{name: %v}", name), Reason: "successRate should be in [0,100] range and maxConsecutiveFailures should be non-negative"}
+		}
+	}
+	return policies, nil
+}
+
 //SetResultAttributes initialise all the chaos result ENV
 func SetResultAttributes(resultDetails *ResultDetails, chaosDetails ChaosDetails) {
 	resultDetails.Verdict = "Awaited"
@@ -305,7 +382,17 @@ func GetValuesFromChaosEngine(chaosDetails *ChaosDetails, clients clients.Client
 	// get all the probes defined inside chaosengine for the corresponding experiment
 	for _, experiment := range engine.Spec.Experiments {
 		if experiment.Name == chaosDetails.ExperimentName {
-			InitializeProbesInChaosResultDetails(chaosresult, experiment.Spec.Probe)
+			// the library probes are replaced with the probe definitions referred by them
+			probes, err := ResolveProbes(chaosDetails, clients, experiment.Spec.Probe)
+			if err != nil {
+				return stacktrace.Propagate(err, "could not resolve the library probes")
+			}
+			// caching the resolved probes, it is non-nil even if no probe is defined
+			chaosDetails.Probes = append([]v1alpha1.ProbeAttributes{}, probes...)
+			if chaosDetails.ProbeEvaluationPolicies, err = getProbeEvaluationPolicies(Getenv("PROBE_EVALUATION_POLICIES", ""), probes); err != nil {
+				return stacktrace.Propagate(err, "could not get the probe evaluation policies")
+			}
+			InitializeProbesInChaosResultDetails(chaosresult, probes)
 			InitializeSidecarDetails(chaosDetails, engine, experiment.Spec.Components.ENV)
 		}
 	}
@@ -395,3 +482,20 @@ func InitializeProbesInChaosResultDetails(chaosresult *ResultDetails, probes []v
 	chaosresult.ProbeDetails = probeDetails
 	chaosresult.ProbeArtifacts = map[string]ProbeArtifact{}
 }
+
+//RegisterAbortHook registers the hook, which is run by the abort watcher before updating the chaosresult
+//it lets the packages, which can't be imported by the abort watcher, stop their routines on abort
+func RegisterAbortHook(hook func()) {
+	abortHooksLock.Lock()
+	defer abortHooksLock.Unlock()
+	abortHooks = append(abortHooks, hook)
+}
+
+//RunAbortHooks runs all the registered abort hooks
+func RunAbortHooks() {
+	abortHooksLock.Lock()
+	defer abortHooksLock.Unlock()
+	for _, hook := range abortHooks {
+		hook()
+	}
+}
@@ -74,6 +74,8 @@ func AbortWatcherWithoutExit(expname string, clients clients.ClientSets, resultD
 	<-signChan
 
 	log.Info("[Chaos]: Chaos Experiment Abortion started because of terminated signal received")
+	// running the registered abort hooks, it stops all the continuous and onchaos probes
+	types.RunAbortHooks()
 	// updating the chaosresult after stopped
 	failStep := "Chaos injection stopped!"
 	types.SetResultAfterCompletion(resultDetails, "Stopped", "Stopped", failStep, cerrors.ErrorTypeExperimentAborted)
@@ -3,16 +3,24 @@ package exec
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/litmuschaos/litmus-go/pkg/cerrors"
 	"github.com/litmuschaos/litmus-go/pkg/clients"
 	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/httpstream"
 
 	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
 // PodDetails contains all the required variables to exec inside a container
@@ -25,12 +33,107 @@ type PodDetails struct {
 // Exec function will run the provide commands inside the target container
 func Exec(commandDetails *PodDetails, clients clients.ClientSets, command []string) (string, string, error) {
 
+	// storing the output inside the output buffer for future use
+	var stdout, stderr bytes.Buffer
+
+	if err := stream(commandDetails, clients, command, &stdout, &stderr, nil); err != nil {
+		if _, ok := err.(cerrors.Error); ok {
+			return "", "", err
+		}
+		return "", "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("failed to create a stderr and stdout stream, %s", err.Error())}
+	}
+
+	return stdout.String(), stderr.String(), nil
+}
+
+// ExecWithTimeout runs the provided command inside the target container and returns the stdout, stderr and exit code of the command
+// the non-zero exit code is not considered as an error. The exec connection is closed, if the command doesn't complete within the timeout
+func ExecWithTimeout(commandDetails *PodDetails, clients clients.ClientSets, command []string, timeout time.Duration) (string, string, int, error) {
+
+	type result struct {
+		stdout, stderr string
+		err            error
+	}
+	// the buffers are owned by the stream goroutine, as it may still be running after the timeout
+	done := make(chan result, 1)
+	closer := &connectionCloser{}
+	go func() {
+		var stdout, stderr bytes.Buffer
+		err := stream(commandDetails, clients, command, &stdout, &stderr, closer)
+		done <- result{stdout: stdout.String(), stderr: stderr.String(), err: err}
+	}()
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			var exitErr utilexec.ExitError
+			if errors.As(res.err, &exitErr) && exitErr.Exited() {
+				return res.stdout, res.stderr, exitErr.ExitStatus(), nil
+			}
+			if _, ok := res.err.(cerrors.Error); ok {
+				return "", "", 0, res.err
+			}
+			return "", "", 0, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("failed to create a stderr and stdout stream, %s", res.err.Error())}
+		}
+		return res.stdout, res.stderr, 0, nil
+	case <-timer:
+		// closing the connection stops the stream, so that the stream goroutine doesn't leak
+		closer.close()
+		return "", "", 0, cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("command did not complete within %v", timeout)}
+	}
+}
+
+// connectionCloser captures the upgraded exec connection, so that it can be closed from outside the stream
+type connectionCloser struct {
+	spdy.Upgrader
+	mu     sync.Mutex
+	conn   httpstream.Connection
+	closed bool
+}
+
+// NewConnection upgrades the response and captures the connection
+// the connection is closed immediately, if the closer is already closed
+func (c *connectionCloser) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	conn, err := c.Upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+	if c.closed {
+		conn.Close()
+	}
+	return conn, nil
+}
+
+// close closes the captured connection
+func (c *connectionCloser) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// stream runs the provided command inside the target container and writes the output into the given writers
+// the exec connection is captured inside the closer, if provided
+func stream(commandDetails *PodDetails, clients clients.ClientSets, command []string, stdout, stderr io.Writer, closer *connectionCloser) error {
+
 	pod, err := clients.KubeClient.CoreV1().Pods(commandDetails.Namespace).Get(context.Background(), commandDetails.PodName, v1.GetOptions{})
 	if err != nil {
-		return "", "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("unable to get %v pod in %v namespace, err: %v", commandDetails.PodName, commandDetails.Namespace, err)}
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("unable to get %v pod in %v namespace, err: %v", commandDetails.PodName, commandDetails.Namespace, err)}
 	}
 	if err := checkPodStatus(pod, commandDetails.ContainerName); err != nil {
-		return "", "", err
+		return err
 	}
 
 	req := clients.KubeClient.CoreV1().RESTClient().Post().
@@ -40,7 +143,7 @@ func Exec(commandDetails *PodDetails, clients clients.ClientSets, command []stri
 		SubResource("exec")
 	scheme := runtime.NewScheme()
 	if err := apiv1.AddToScheme(scheme); err != nil {
-		return "", "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("error adding to scheme: %v", err)}
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("error adding to scheme: %v", err)}
 	}
 
 	// NewParameterCodec creates a ParameterCodec capable of transforming url values into versioned objects and back.
@@ -57,25 +160,26 @@ func Exec(commandDetails *PodDetails, clients clients.ClientSets, command []stri
 
 	// NewSPDYExecutor connects to the provided server and upgrades the connection to
 	// multiplexed bidirectional streams.
-	exec, err := remotecommand.NewSPDYExecutor(clients.KubeConfig, "POST", req.URL())
+	transport, upgrader, err := spdy.RoundTripperFor(clients.KubeConfig)
 	if err != nil {
-		return "", "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("error while creating Executor: %v", err)}
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("error while creating Executor: %v", err)}
+	}
+	if closer != nil {
+		closer.Upgrader = upgrader
+		upgrader = closer
+	}
+	exec, err := remotecommand.NewSPDYExecutorForTransports(transport, upgrader, "POST", req.URL())
+	if err != nil {
+		return cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("error while creating Executor: %v", err)}
 	}
-
-	// storing the output inside the output buffer for future use
-	var stdout, stderr bytes.Buffer
 
 	// Stream will initiate the transport of the standard shell streams and return an error if a problem occurs.
-	if err = exec.Stream(remotecommand.StreamOptions{
+	return exec.Stream(remotecommand.StreamOptions{
 		Stdin:  nil,
-		Stdout: &stdout,
-		Stderr: &stderr,
+		Stdout: stdout,
+		Stderr: stderr,
 		Tty:    false,
-	}); err != nil {
-		return "", "", cerrors.Error{ErrorCode: cerrors.ErrorTypeGeneric, Reason: fmt.Sprintf("failed to create a stderr and stdout stream, %s", err.Error())}
-	}
-
-	return stdout.String(), stderr.String(), nil
+	})
 }
 
 // SetExecCommandAttributes initialise all the pod details  to run exec command
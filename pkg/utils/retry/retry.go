@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"fmt"
 	"github.com/litmuschaos/litmus-go/pkg/cerrors"
 	"time"
@@ -16,6 +17,7 @@ type Model struct {
 	retry    uint
 	waitTime time.Duration
 	timeout  int64
+	ctx      context.Context
 }
 
 // Times is used to define the retry count
@@ -60,6 +62,13 @@ func (model *Model) Timeout(timeout int64) *Model {
 	return model
 }
 
+// Context is used to define the context, the retries are stopped once the context is cancelled
+// it will run if the instance of model is already present
+func (model *Model) Context(ctx context.Context) *Model {
+	model.ctx = ctx
+	return model
+}
+
 // Try is used to run a action with retries and some delay after each iteration
 func (model Model) Try(action Action) error {
 	if action == nil {
@@ -68,9 +77,15 @@ func (model Model) Try(action Action) error {
 
 	var err error
 	for attempt := uint(0); (attempt == 0 || err != nil) && attempt <= model.retry; attempt++ {
+		if model.cancelled() {
+			if err == nil {
+				err = model.ctx.Err()
+			}
+			break
+		}
 		err = action(attempt)
-		if model.waitTime > 0 {
-			time.Sleep(model.waitTime)
+		if model.waitTime > 0 && !model.sleep() {
+			break
 		}
 		if err == errors.Errorf("container is in terminated state") {
 			break
@@ -89,6 +104,12 @@ func (model Model) TryWithTimeout(action Action) error {
 	var err error
 	err = nil
 	for attempt := uint(0); (attempt == 0 || err != nil) && attempt < model.retry; {
+		if model.cancelled() {
+			if err == nil {
+				err = model.ctx.Err()
+			}
+			break
+		}
 		startTime := time.Now().UnixMilli()
 		err = action(attempt)
 		if err == nil && time.Now().UnixMilli()-startTime >= model.timeout {
@@ -98,10 +119,31 @@ func (model Model) TryWithTimeout(action Action) error {
 			}
 		}
 		attempt++
-		if model.waitTime > 0 && attempt < model.retry {
-			time.Sleep(model.waitTime)
+		if model.waitTime > 0 && attempt < model.retry && !model.sleep() {
+			break
 		}
 	}
 
 	return err
 }
+
+// cancelled checks whether the context is cancelled before each attempt
+func (model Model) cancelled() bool {
+	return model.ctx != nil && model.ctx.Err() != nil
+}
+
+// sleep waits for the wait duration, it returns false if the context is cancelled meanwhile
+func (model Model) sleep() bool {
+	if model.ctx == nil {
+		time.Sleep(model.waitTime)
+		return true
+	}
+	timer := time.NewTimer(model.waitTime)
+	defer timer.Stop()
+	select {
+	case <-model.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}